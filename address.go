@@ -3,15 +3,33 @@ package emailverifier
 import (
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
 )
 
 var emailRegex = regexp.MustCompile(emailRegexString)
 
+// The SMTP limits of RFC 5321, longer addresses always bounce
+const (
+	maxLocalPartLength = 64
+	maxDomainLength    = 253
+)
+
 // Syntax stores all information about an email Syntax
 type Syntax struct {
-	Username string `json:"username"`
-	Domain   string `json:"domain"`
-	Valid    bool   `json:"valid"`
+	Username         string `json:"username"`
+	Domain           string `json:"domain"`
+	Valid            bool   `json:"valid"`
+	BaseUsername     string `json:"base_username"`       // username stripped of the plus-addressing tag
+	HasPlusTag       bool   `json:"has_plus_tag"`        // whether the username uses plus-addressing (user+tag)
+	Tag              string `json:"tag"`                 // the plus-addressing tag
+	LocalPartTooLong bool   `json:"local_part_too_long"` // the username exceeds 64 octets
+	DomainTooLong    bool   `json:"domain_too_long"`     // the domain exceeds 253 octets in its ASCII form
+	InvalidIDN       bool   `json:"invalid_idn"`         // the internationalized domain is malformed or mixes scripts in a label
+	SMTPUTF8         bool   `json:"smtputf8"`            // the username is not ASCII, so the delivery requires SMTPUTF8 (RFC 6531)
+	Reason           string `json:"reason,omitempty"`    // the failed rule of an invalid address, when known
 }
 
 // ParseAddress attempts to parse an email address and return it in the form of an Syntax
@@ -25,12 +43,110 @@ func (v *Verifier) ParseAddress(email string) Syntax {
 	index := strings.LastIndex(email, "@")
 	username := email[:index]
 	domain := strings.ToLower(email[index+1:])
+	if syntax := checkLength(username, domain); syntax.Reason != "" {
+		return syntax
+	}
+	if reason := checkIDN(domain); reason != "" {
+		return Syntax{InvalidIDN: true, Reason: reason}
+	}
+
+	baseUsername, tag, hasPlusTag := splitPlusTag(username)
 
 	return Syntax{
-		Username: username,
-		Domain:   domain,
-		Valid:    isAddressValid,
+		Username:     username,
+		Domain:       domain,
+		Valid:        isAddressValid,
+		BaseUsername: baseUsername,
+		HasPlusTag:   hasPlusTag,
+		Tag:          tag,
+		SMTPUTF8:     !isASCII(username),
+	}
+}
+
+// checkLength checks the username and the domain fit the SMTP length limits,
+// the returned Syntax has the Reason set when they do not
+func checkLength(username, domain string) Syntax {
+	var syntax Syntax
+	if len(DomainToASCII(domain)) > maxDomainLength {
+		syntax.DomainTooLong = true
+		syntax.Reason = SyntaxErrDomainTooLong
+	}
+	if len(username) > maxLocalPartLength {
+		syntax.LocalPartTooLong = true
+		syntax.Reason = SyntaxErrLocalPartTooLong
+	}
+	return syntax
+}
+
+// idnScripts are the scripts whose letters are confusable with each other, a label mixing them
+// is a sign of a spoofed domain
+var idnScripts = []*unicode.RangeTable{unicode.Latin, unicode.Cyrillic, unicode.Greek}
+
+// checkIDN checks the punycode labels of the domain round-trip to the same Unicode form
+// and do not mix the scripts, it returns the failed rule or an empty string
+func checkIDN(domain string) string {
+	asciiDomain, err := DomainToASCIIE(domain)
+	if err != nil {
+		return SyntaxErrInvalidIDN
+	}
+
+	for _, label := range strings.Split(asciiDomain, ".") {
+		if !strings.HasPrefix(label, "xn--") {
+			continue
+		}
+		u, err := idna.Lookup.ToUnicode(label)
+		if err != nil {
+			return SyntaxErrInvalidIDN
+		}
+		if a, err := idna.Lookup.ToASCII(u); err != nil || a != label {
+			return SyntaxErrInvalidIDN
+		}
+		if isMixedScript(u) {
+			return SyntaxErrMixedScriptIDN
+		}
+	}
+	return ""
+}
+
+// isMixedScript checks if the label contains letters of more than one of the confusable scripts
+func isMixedScript(label string) bool {
+	var script *unicode.RangeTable
+	for _, r := range label {
+		for _, t := range idnScripts {
+			if !unicode.Is(t, r) {
+				continue
+			}
+			if script != nil && script != t {
+				return true
+			}
+			script = t
+		}
+	}
+	return false
+}
+
+// isASCII checks the string consists of ASCII characters only
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPlusTag splits the username into the base username and the plus-addressing tag,
+// a quoted username may legitimately contain '+' and is never split
+func splitPlusTag(username string) (string, string, bool) {
+	if strings.HasPrefix(username, `"`) {
+		return username, "", false
+	}
+
+	i := strings.Index(username, "+")
+	if i <= 0 {
+		return username, "", false
 	}
+	return username[:i], username[i+1:], true
 }
 
 // IsAddressValid checks if email address is formatted correctly by using regex
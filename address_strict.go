@@ -0,0 +1,302 @@
+package emailverifier
+
+import (
+	"net"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The rules of RFC 5322 violated by an address, reported in Syntax.Reason by ParseAddressStrict
+const (
+	SyntaxErrMissingAt          = "missing @ separating the local part and the domain"
+	SyntaxErrEmptyLocalPart     = "empty local part"
+	SyntaxErrLocalPartTooLong   = "local part exceeds 64 octets"
+	SyntaxErrLeadingDot         = "local part starts with a dot"
+	SyntaxErrTrailingDot        = "local part ends with a dot"
+	SyntaxErrConsecutiveDots    = "local part contains consecutive dots"
+	SyntaxErrInvalidCharacter   = "local part contains a disallowed character"
+	SyntaxErrUnterminatedQuote  = "quoted local part is not terminated"
+	SyntaxErrInvalidQuotedPart  = "quoted local part contains a disallowed character"
+	SyntaxErrUnbalancedComment  = "comment is not terminated"
+	SyntaxErrEmptyDomain        = "empty domain"
+	SyntaxErrDomainTooLong      = "domain exceeds 253 octets"
+	SyntaxErrInvalidDomainLabel = "domain contains an invalid label"
+	SyntaxErrInvalidDomainIP    = "domain literal is not a valid IP address"
+	SyntaxErrInvalidIDN         = "domain is not a valid internationalized domain name"
+	SyntaxErrMixedScriptIDN     = "domain label mixes scripts"
+)
+
+const (
+	maxLabelLength = 63
+
+	// atext of RFC 5322 besides letters and digits
+	atextSpecials = "!#$%&'*+-/=?^_`{|}~"
+)
+
+// ParseAddressStrict parses an email address following the addr-spec of RFC 5322:
+// the local part is either a dot-atom or a quoted string, comments around the local part
+// and the domain are removed, and the domain is either a host name or an IP address literal.
+// Unlike ParseAddress, the rule failed by an invalid address is reported in Syntax.Reason.
+func (v *Verifier) ParseAddressStrict(email string) Syntax {
+	at := lastUnquotedAt(email)
+	if at < 0 {
+		return Syntax{Reason: SyntaxErrMissingAt}
+	}
+
+	username, err := stripComments(email[:at])
+	if err != "" {
+		return Syntax{Reason: err}
+	}
+	domain, err := stripComments(email[at+1:])
+	if err != "" {
+		return Syntax{Reason: err}
+	}
+
+	domain = strings.ToLower(domain)
+	if syntax := checkLength(username, domain); syntax.Reason != "" {
+		return syntax
+	}
+	if reason := checkLocalPart(username); reason != "" {
+		return Syntax{Reason: reason}
+	}
+	if reason := checkDomain(domain); reason != "" {
+		return Syntax{Reason: reason}
+	}
+	if reason := checkIDN(domain); reason != "" {
+		return Syntax{InvalidIDN: true, Reason: reason}
+	}
+
+	baseUsername, tag, hasPlusTag := splitPlusTag(username)
+
+	return Syntax{
+		Username:     username,
+		Domain:       domain,
+		Valid:        true,
+		BaseUsername: baseUsername,
+		HasPlusTag:   hasPlusTag,
+		Tag:          tag,
+		SMTPUTF8:     !isASCII(username),
+	}
+}
+
+// parseAddress parses the address by ParseAddressStrict when the strict syntax is enabled,
+// by ParseAddress otherwise
+func (v *Verifier) parseAddress(email string) Syntax {
+	if v.strictSyntaxEnabled {
+		return v.ParseAddressStrict(email)
+	}
+	return v.ParseAddress(email)
+}
+
+// lastUnquotedAt returns the index of the last '@' outside of a quoted string and comments
+func lastUnquotedAt(email string) int {
+	at := -1
+	quoted, depth := false, 0
+	for i := 0; i < len(email); i++ {
+		switch c := email[i]; {
+		case c == '\\' && (quoted || depth > 0):
+			i++
+		case c == '"' && depth == 0:
+			quoted = !quoted
+		case c == '(' && !quoted:
+			depth++
+		case c == ')' && !quoted && depth > 0:
+			depth--
+		case c == '@' && !quoted && depth == 0:
+			at = i
+		}
+	}
+	return at
+}
+
+// stripComments removes the comments (and the surrounding white space) at the beginning
+// and at the end of the part, comments elsewhere are left and fail the later checks
+func stripComments(part string) (string, string) {
+	for {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "(") {
+			end := commentEnd(part)
+			if end < 0 {
+				return "", SyntaxErrUnbalancedComment
+			}
+			part = part[end+1:]
+			continue
+		}
+		if strings.HasSuffix(part, ")") {
+			start := commentStart(part)
+			if start < 0 {
+				return "", SyntaxErrUnbalancedComment
+			}
+			part = part[:start]
+			continue
+		}
+		return part, ""
+	}
+}
+
+// commentEnd returns the index of the parenthesis closing the comment the part starts with
+func commentEnd(part string) int {
+	depth := 0
+	for i := 0; i < len(part); i++ {
+		switch part[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// commentStart returns the index of the parenthesis opening the comment the part ends with
+func commentStart(part string) int {
+	depth := 0
+	for i := len(part) - 1; i >= 0; i-- {
+		if i > 0 && part[i-1] == '\\' {
+			continue
+		}
+		switch part[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// checkLocalPart checks the local part is a valid dot-atom or quoted string
+func checkLocalPart(local string) string {
+	if local == "" {
+		return SyntaxErrEmptyLocalPart
+	}
+
+	if strings.HasPrefix(local, `"`) {
+		return checkQuotedString(local)
+	}
+
+	if strings.HasPrefix(local, ".") {
+		return SyntaxErrLeadingDot
+	}
+	if strings.HasSuffix(local, ".") {
+		return SyntaxErrTrailingDot
+	}
+	if strings.Contains(local, "..") {
+		return SyntaxErrConsecutiveDots
+	}
+	for _, r := range local {
+		if r < utf8.RuneSelf && r != '.' && !isAtext(byte(r)) || r >= utf8.RuneSelf && !isUTF8NonASCII(r) {
+			return SyntaxErrInvalidCharacter
+		}
+	}
+	return ""
+}
+
+// checkQuotedString checks the local part is a single quoted string of qtext and quoted-pairs
+func checkQuotedString(local string) string {
+	for i := 1; i < len(local); i++ {
+		c := local[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(local[i:])
+			if !isUTF8NonASCII(r) {
+				return SyntaxErrInvalidQuotedPart
+			}
+			i += size - 1
+			continue
+		}
+		switch {
+		case c == '\\':
+			i++
+			if i >= len(local) || !(isVchar(local[i]) || local[i] == ' ' || local[i] == '\t') {
+				return SyntaxErrInvalidQuotedPart
+			}
+		case c == '"':
+			if i != len(local)-1 {
+				return SyntaxErrInvalidCharacter
+			}
+			return ""
+		case !(isVchar(c) || c == ' ' || c == '\t'):
+			return SyntaxErrInvalidQuotedPart
+		}
+	}
+	return SyntaxErrUnterminatedQuote
+}
+
+// checkDomain checks the domain is a valid host name (internationalized names included)
+// or an IP address literal
+func checkDomain(domain string) string {
+	if domain == "" {
+		return SyntaxErrEmptyDomain
+	}
+
+	if strings.HasPrefix(domain, "[") && strings.HasSuffix(domain, "]") {
+		literal := domain[1 : len(domain)-1]
+		if strings.HasPrefix(literal, "ipv6:") {
+			ip := net.ParseIP(literal[len("ipv6:"):])
+			if ip == nil || ip.To4() != nil && !strings.Contains(literal[len("ipv6:"):], ":") {
+				return SyntaxErrInvalidDomainIP
+			}
+			return ""
+		}
+		if ip := net.ParseIP(literal); ip == nil || ip.To4() == nil || strings.Contains(literal, ":") {
+			return SyntaxErrInvalidDomainIP
+		}
+		return ""
+	}
+
+	asciiDomain, err := DomainToASCIIE(domain)
+	if err != nil {
+		return SyntaxErrInvalidIDN
+	}
+	labels := strings.Split(asciiDomain, ".")
+	if len(labels) < 2 {
+		return SyntaxErrInvalidDomainLabel
+	}
+	for _, label := range labels {
+		if !isHostLabel(label) {
+			return SyntaxErrInvalidDomainLabel
+		}
+	}
+	return ""
+}
+
+// isHostLabel checks the label consists of letters, digits and hyphens, not at its ends
+func isHostLabel(label string) bool {
+	if label == "" || len(label) > maxLabelLength || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if !(isAlphaNum(c) || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAtext(c byte) bool {
+	return isAlphaNum(c) || strings.IndexByte(atextSpecials, c) >= 0
+}
+
+func isAlphaNum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// isUTF8NonASCII checks the rune is a printable non-ASCII character, allowed in the local part
+// of the internationalized addresses (RFC 6532)
+func isUTF8NonASCII(r rune) bool {
+	return r >= utf8.RuneSelf && r != utf8.RuneError && unicode.IsPrint(r)
+}
+
+func isVchar(c byte) bool {
+	return c >= 0x21 && c <= 0x7e
+}
@@ -0,0 +1,96 @@
+package emailverifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAddressStrict(t *testing.T) {
+	cases := []struct {
+		mail   string
+		reason string
+	}{
+		{mail: "example@domain.com"},
+		{mail: "first.last@domain.com"},
+		{mail: "a!#$%&'*+-/=?^_`{|}~z@domain.com"},
+		{mail: `"john doe"@domain.com`},
+		{mail: `"john\"doe"@domain.com`},
+		{mail: `"a@b"@domain.com`},
+		{mail: "(comment)john@domain.com"},
+		{mail: "john(nested (comment))@(comment)domain.com"},
+		{mail: "john@[192.168.0.1]"},
+		{mail: "john@[IPv6:2001:db8::1]"},
+		{mail: "用户@例子.com"},
+		{mail: "😀@gmail.com"},
+		{mail: `"用户 名"@domain.com`},
+		{mail: "abc@доменное.com"},
+		{mail: strings.Repeat("a", 64) + "@domain.com"},
+		{mail: "john.domain.com", reason: SyntaxErrMissingAt},
+		{mail: "@domain.com", reason: SyntaxErrEmptyLocalPart},
+		{mail: strings.Repeat("a", 65) + "@domain.com", reason: SyntaxErrLocalPartTooLong},
+		{mail: ".john@domain.com", reason: SyntaxErrLeadingDot},
+		{mail: "john.@domain.com", reason: SyntaxErrTrailingDot},
+		{mail: "john..doe@domain.com", reason: SyntaxErrConsecutiveDots},
+		{mail: "john doe@domain.com", reason: SyntaxErrInvalidCharacter},
+		{mail: "john,doe@domain.com", reason: SyntaxErrInvalidCharacter},
+		{mail: "\xffjohn@domain.com", reason: SyntaxErrInvalidCharacter},
+		{mail: "john\u00a0doe@domain.com", reason: SyntaxErrInvalidCharacter},
+		{mail: "\"jo\xffhn\"@domain.com", reason: SyntaxErrInvalidQuotedPart},
+		{mail: `"john"doe@domain.com`, reason: SyntaxErrInvalidCharacter},
+		{mail: `"john@domain.com`, reason: SyntaxErrMissingAt},
+		{mail: `"john\"@domain.com`, reason: SyntaxErrMissingAt},
+		{mail: "\"jo\x01hn\"@domain.com", reason: SyntaxErrInvalidQuotedPart},
+		{mail: "(comment john@domain.com", reason: SyntaxErrMissingAt},
+		{mail: "john@(comment domain.com", reason: SyntaxErrUnbalancedComment},
+		{mail: "john@", reason: SyntaxErrEmptyDomain},
+		{mail: "john@" + strings.Repeat("a.", 128) + "com", reason: SyntaxErrDomainTooLong},
+		{mail: "john@domain", reason: SyntaxErrInvalidDomainLabel},
+		{mail: "john@domain..com", reason: SyntaxErrInvalidDomainLabel},
+		{mail: "john@-domain.com", reason: SyntaxErrInvalidDomainLabel},
+		{mail: "john@dom_ain.com", reason: SyntaxErrInvalidDomainLabel},
+		{mail: "john@" + strings.Repeat("a", 64) + ".com", reason: SyntaxErrInvalidDomainLabel},
+		{mail: "john@xn--zz.com", reason: SyntaxErrInvalidIDN},
+		{mail: "john@xn--a-ccb.com", reason: SyntaxErrInvalidIDN},
+		{mail: "john@p\u0430ypal.com", reason: SyntaxErrMixedScriptIDN},
+		{mail: "john@[300.0.0.1]", reason: SyntaxErrInvalidDomainIP},
+		{mail: "john@[2001:db8::1]", reason: SyntaxErrInvalidDomainIP},
+	}
+
+	for _, c := range cases {
+		syntax := verifier.ParseAddressStrict(c.mail)
+		assert.Equal(t, c.reason == "", syntax.Valid, c.mail)
+		assert.Equal(t, c.reason, syntax.Reason, c.mail)
+	}
+}
+
+func TestParseAddressStrict_Parts(t *testing.T) {
+	syntax := verifier.ParseAddressStrict("(work) John+News@(primary) Domain.COM")
+
+	assert.True(t, syntax.Valid)
+	assert.Equal(t, "John+News", syntax.Username)
+	assert.Equal(t, "domain.com", syntax.Domain)
+	assert.Equal(t, "John", syntax.BaseUsername)
+	assert.Equal(t, "News", syntax.Tag)
+	assert.True(t, syntax.HasPlusTag)
+}
+
+func TestParseAddressStrict_SMTPUTF8(t *testing.T) {
+	assert.True(t, verifier.ParseAddressStrict("用户@例子.com").SMTPUTF8)
+	assert.False(t, verifier.ParseAddressStrict("user@例子.com").SMTPUTF8)
+}
+
+func TestVerify_StrictSyntax(t *testing.T) {
+	v := NewVerifier().EnableStrictSyntax()
+
+	ret, err := v.Verify("john..doe@domain.com")
+	assert.NoError(t, err)
+	assert.False(t, ret.Syntax.Valid)
+	assert.Equal(t, SyntaxErrConsecutiveDots, ret.Syntax.Reason)
+
+	ret, err = v.DisableStrictSyntax().Verify("john..doe@domain.com")
+	assert.NoError(t, err)
+	assert.False(t, ret.Syntax.Valid)
+	assert.Empty(t, ret.Syntax.Reason)
+}
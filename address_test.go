@@ -1,6 +1,7 @@
 package emailverifier
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -17,6 +18,7 @@ var (
 		{mail: "user@gma3il.com", format: true},
 		{mail: "a_b@github.com", format: true},
 		{mail: "abc@доменное.com", format: true},
+		{mail: "用户@例子.com", format: true},
 	}
 )
 
@@ -31,3 +33,107 @@ func TestCheckAddressSyntax(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAddress_PlusTag(t *testing.T) {
+	cases := []struct {
+		mail         string
+		baseUsername string
+		tag          string
+		hasPlusTag   bool
+	}{
+		{mail: "user+news@example.com", baseUsername: "user", tag: "news", hasPlusTag: true},
+		{mail: "user+a+b@example.com", baseUsername: "user", tag: "a+b", hasPlusTag: true},
+		{mail: "user+@example.com", baseUsername: "user", tag: "", hasPlusTag: true},
+		{mail: "user@example.com", baseUsername: "user"},
+		{mail: "+user@example.com", baseUsername: "+user"},
+		{mail: `"user+news"@example.com`, baseUsername: `"user+news"`},
+	}
+
+	for _, s := range cases {
+		address := verifier.ParseAddress(s.mail)
+		if !address.Valid {
+			t.Errorf(`"%s" check failed with an unexpected error`, s.mail)
+			continue
+		}
+		if address.BaseUsername != s.baseUsername || address.Tag != s.tag || address.HasPlusTag != s.hasPlusTag {
+			t.Errorf(`"%s" => unexpected plus tag parsing: %+v`, s.mail, address)
+		}
+	}
+}
+
+func TestParseAddress_LengthLimits(t *testing.T) {
+	// 5 labels of 49 octets with their dots take 250 octets
+	domain253 := strings.Repeat(strings.Repeat("a", 49)+".", 5) + "com"
+	// 220 characters, but 276 octets once encoded to punycode
+	longIDNDomain := strings.Repeat(strings.Repeat("äöü", 10)+".", 7) + "com"
+	// 403 octets in UTF-8, but 238 octets once encoded to punycode
+	shortIDNDomain := strings.Repeat(strings.Repeat("ä", 40)+".", 5) + "com"
+
+	cases := []struct {
+		mail             string
+		localPartTooLong bool
+		domainTooLong    bool
+	}{
+		{mail: strings.Repeat("a", 64) + "@example.com"},
+		{mail: strings.Repeat("a", 65) + "@example.com", localPartTooLong: true},
+		{mail: "user@" + domain253},
+		{mail: "user@" + domain253 + "m", domainTooLong: true},
+		{mail: "user@" + longIDNDomain, domainTooLong: true},
+		{mail: "user@" + shortIDNDomain},
+		{mail: strings.Repeat("a", 65) + "@" + domain253 + "m", localPartTooLong: true, domainTooLong: true},
+	}
+
+	for _, v := range []func(string) Syntax{verifier.ParseAddress, verifier.ParseAddressStrict} {
+		for _, s := range cases {
+			address := v(s.mail)
+			if address.Valid != (!s.localPartTooLong && !s.domainTooLong) {
+				t.Errorf(`"%s" => unexpected validity: %+v`, s.mail, address)
+			}
+			if address.LocalPartTooLong != s.localPartTooLong || address.DomainTooLong != s.domainTooLong {
+				t.Errorf(`"%s" => unexpected length check: %+v`, s.mail, address)
+			}
+		}
+	}
+}
+
+func TestParseAddress_InvalidIDN(t *testing.T) {
+	cases := []struct {
+		mail   string
+		reason string
+	}{
+		{mail: "user@münchen.de"},
+		{mail: "user@xn--mnchen-3ya.de"},
+		{mail: "user@доменное.com"},
+		{mail: "user@xn--zz.com", reason: SyntaxErrInvalidIDN},
+		// decomposed "ä" that does not round-trip to its normalized form
+		{mail: "user@xn--a-ccb.com", reason: SyntaxErrInvalidIDN},
+		// Latin with the Cyrillic "а"
+		{mail: "user@p\u0430ypal.com", reason: SyntaxErrMixedScriptIDN},
+	}
+
+	for _, s := range cases {
+		address := verifier.ParseAddress(s.mail)
+		if address.Valid != (s.reason == "") || address.InvalidIDN != (s.reason != "") || address.Reason != s.reason {
+			t.Errorf(`"%s" => unexpected syntax: %+v`, s.mail, address)
+		}
+	}
+}
+
+func TestParseAddress_SMTPUTF8(t *testing.T) {
+	cases := []struct {
+		mail     string
+		smtpUTF8 bool
+	}{
+		{mail: "user@example.com"},
+		{mail: "user@例子.com"},
+		{mail: "用户@例子.com", smtpUTF8: true},
+		{mail: "josé@example.com", smtpUTF8: true},
+	}
+
+	for _, s := range cases {
+		address := verifier.ParseAddress(s.mail)
+		if !address.Valid || address.SMTPUTF8 != s.smtpUTF8 {
+			t.Errorf(`"%s" => unexpected syntax: %+v`, s.mail, address)
+		}
+	}
+}
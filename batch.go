@@ -0,0 +1,162 @@
+package emailverifier
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// BatchVerify verifies the emails concurrently with at most concurrency workers.
+// Results preserve the order of the passed emails, and a per-email failure is
+// attached to its Result instead of aborting the whole batch.
+func (v *Verifier) BatchVerify(emails []string, concurrency int) ([]*Result, error) {
+	return v.BatchVerifyContext(context.Background(), emails, concurrency)
+}
+
+// BatchVerifyContext is like BatchVerify but stops dispatching emails once ctx is done.
+// Emails which were not verified carry the context error in their Result.
+// When the SMTP connections are reused, the emails at the same domain are verified by the same worker.
+func (v *Verifier) BatchVerifyContext(ctx context.Context, emails []string, concurrency int) ([]*Result, error) {
+	v = v.snapshot()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]*Result, len(emails))
+	jobs := make(chan []int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := v
+			if v.smtpReuseEnabled {
+				w = v.withSMTPSession()
+				defer w.smtpSession.close()
+			}
+			for group := range jobs {
+				for _, idx := range group {
+					if ctx.Err() != nil {
+						break
+					}
+					results[idx] = w.verifyForBatch(emails[idx])
+				}
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for _, group := range v.batchGroups(emails) {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case jobs <- group:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, r := range results {
+		if r == nil {
+			if err == nil {
+				err = ctx.Err()
+			}
+			results[i] = &Result{
+				Email:     emails[i],
+				Reachable: reachableUnknown,
+				Error:     err.Error(),
+			}
+		}
+	}
+
+	return results, err
+}
+
+// VerifyStream verifies the emails received from in concurrently with at most concurrency workers and sends
+// their Results to out as they complete, i.e. not in the input order, so that large inputs are verified
+// without holding all the results in memory. It blocks until in is closed and drained or ctx is done,
+// then closes out. The emails left unverified due to ctx get no Result and the context error is returned.
+func (v *Verifier) VerifyStream(ctx context.Context, in <-chan string, out chan<- *Result, concurrency int) error {
+	v = v.snapshot()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	defer close(out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := v
+			if v.smtpReuseEnabled {
+				w = v.withSMTPSession()
+				defer w.smtpSession.close()
+			}
+			for {
+				var email string
+				var ok bool
+				select {
+				case email, ok = <-in:
+					if !ok {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+
+				select {
+				case out <- w.verifyForBatch(email):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// verifyForBatch verifies the email and attaches the verification error to the result
+func (v *Verifier) verifyForBatch(email string) *Result {
+	ret, err := v.verifyEmail(email)
+	if ret == nil {
+		ret = &Result{Email: email, Reachable: reachableUnknown}
+	}
+	if err != nil {
+		ret.Error = err.Error()
+	}
+	return ret
+}
+
+// batchGroups splits the indexes of the emails into the groups verified sequentially by a worker,
+// the emails at the same domain form a group when the SMTP connections are reused
+func (v *Verifier) batchGroups(emails []string) [][]int {
+	groups := make([][]int, 0, len(emails))
+	if !v.smtpReuseEnabled {
+		for i := range emails {
+			groups = append(groups, []int{i})
+		}
+		return groups
+	}
+
+	byDomain := make(map[string]int)
+	for i, email := range emails {
+		domain := strings.ToLower(email[strings.LastIndexByte(email, '@')+1:])
+		g, ok := byDomain[domain]
+		if !ok {
+			g = len(groups)
+			byDomain[domain] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
@@ -0,0 +1,105 @@
+package emailverifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchVerifyOK_PreservesOrder(t *testing.T) {
+	dr := newDisposableRepo()
+	dr.AddDisposableDomains([]string{"disposable.test"})
+	verifier := NewVerifier().EnableDisposableCheck(dr)
+
+	emails := []string{"invalid", "a@disposable.test", "@disposable.test", "b@disposable.test"}
+	results, err := verifier.BatchVerify(emails, 3)
+	assert.NoError(t, err)
+	assert.Len(t, results, len(emails))
+	for i, r := range results {
+		assert.Equal(t, emails[i], r.Email)
+		assert.Empty(t, r.Error)
+	}
+	assert.False(t, results[0].Syntax.Valid)
+	assert.True(t, results[1].Disposable)
+	assert.False(t, results[2].Syntax.Valid)
+	assert.True(t, results[3].Disposable)
+}
+
+// TestBatchVerifyOK_VerifyTimeout shares the snapshot across the workers, each Verify keeping its own budget, run it with -race
+func TestBatchVerifyOK_VerifyTimeout(t *testing.T) {
+	emails := []string{"a@example.test", "b@example.test", "c@example.test", "d@example.test"}
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT(emails...)
+	verifier := srv.verifier("example.test").VerifyTimeout(time.Minute)
+
+	results, err := verifier.BatchVerify(emails, 4)
+	assert.NoError(t, err)
+	for i, r := range results {
+		assert.Equal(t, emails[i], r.Email)
+		assert.Empty(t, r.Error)
+		assert.Equal(t, reachableYes, r.Reachable)
+	}
+	assert.Nil(t, verifier.ctx)
+}
+
+func TestBatchVerifyFailed_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	emails := []string{"a@example.com", "b@example.com"}
+	results, err := NewVerifier().BatchVerifyContext(ctx, emails, 2)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, results, len(emails))
+	for i, r := range results {
+		assert.Equal(t, emails[i], r.Email)
+		assert.Equal(t, reachableUnknown, r.Reachable)
+		assert.Equal(t, context.Canceled.Error(), r.Error)
+	}
+}
+
+func TestVerifyStreamOK(t *testing.T) {
+	dr := newDisposableRepo()
+	dr.AddDisposableDomains([]string{"disposable.test"})
+	verifier := NewVerifier().EnableDisposableCheck(dr)
+
+	emails := []string{"invalid", "a@disposable.test", "@disposable.test", "b@disposable.test"}
+	in := make(chan string)
+	out := make(chan *Result)
+	go func() {
+		for _, email := range emails {
+			in <- email
+		}
+		close(in)
+	}()
+
+	errc := make(chan error, 1)
+	go func() { errc <- verifier.VerifyStream(context.Background(), in, out, 3) }()
+
+	results := map[string]*Result{}
+	for r := range out {
+		results[r.Email] = r
+	}
+	assert.NoError(t, <-errc)
+	assert.Len(t, results, len(emails))
+	assert.False(t, results["invalid"].Syntax.Valid)
+	assert.True(t, results["a@disposable.test"].Disposable)
+	assert.False(t, results["@disposable.test"].Syntax.Valid)
+	assert.True(t, results["b@disposable.test"].Disposable)
+}
+
+func TestVerifyStreamFailed_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := make(chan string)
+	out := make(chan *Result)
+	errc := make(chan error, 1)
+	go func() { errc <- NewVerifier().VerifyStream(ctx, in, out, 2) }()
+
+	for r := range out {
+		t.Errorf("unexpected result of %s", r.Email)
+	}
+	assert.ErrorIs(t, <-errc, context.Canceled)
+}
@@ -8,7 +8,17 @@ const (
 	defaultHelloName = "localhost"
 
 	smtpTimeout = 30 * time.Second
-	smtpPort    = ":25"
+	smtpPort    = "25"
+
+	defaultTarpitThreshold = 10 * time.Second
+
+	submissionPort = "587"
+	imapsPort      = "993"
+	pop3sPort      = "995"
+
+	proxyFailureCooldown = time.Minute
+
+	batchMXCacheTTL = 10 * time.Minute
 
 	reachableYes     = "yes"
 	reachableNo      = "no"
@@ -18,10 +28,19 @@ const (
 
 	disposableDataURL = "https://raw.githubusercontent.com/disposable/disposable-email-domains/master/domains.json"
 
-	gravatarBaseUrl    = "https://www.gravatar.com/avatar/"
+	defaultDisposableUpdateInterval = 24 * time.Hour
+	minDisposableUpdateInterval     = 10 * time.Minute
+	defaultDisposableFetchTimeout   = 5 * time.Second
+	defaultDisposableMaxSize        = 50 << 20
+
+	gravatarBaseUrl    = "https://www.gravatar.com"
 	gravatarDefaultMd5 = "d5fe5cbcc31cff5f8ac010db72eb000c"
 
+	rdapBootstrapURL = "https://data.iana.org/rdap/dns.json"
+	rdapBootstrapTTL = 24 * time.Hour
+
 	domainThreshold      float32 = 0.82
 	secondLevelThreshold float32 = 0.82
 	topLevelThreshold    float32 = 0.6
+	usernameThreshold    float32 = 0.75
 )
@@ -0,0 +1,59 @@
+package emailverifier
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvHeader is the header row written by WriteResultsCSV
+var csvHeader = []string{
+	"email",
+	"reachable",
+	"has_mx_records",
+	"disposable",
+	"role_account",
+	"free",
+	"smtp_deliverable",
+	"smtp_catch_all",
+	"suggestion",
+	"error",
+}
+
+// WriteResultsCSV writes the results, e.g. of BatchVerify, as CSV with a header row.
+// The details of the checks which were not performed are written as empty cells.
+func WriteResultsCSV(w io.Writer, results []*Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := cw.Write(csvRecord(r)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRecord flattens the result into the columns of csvHeader
+func csvRecord(r *Result) []string {
+	record := make([]string, len(csvHeader))
+	if r == nil {
+		return record
+	}
+
+	record[0] = r.Email
+	record[1] = r.Reachable
+	record[2] = strconv.FormatBool(r.HasMxRecords)
+	record[3] = strconv.FormatBool(r.Disposable)
+	record[4] = strconv.FormatBool(r.RoleAccount)
+	record[5] = strconv.FormatBool(r.Free)
+	if r.SMTP != nil {
+		record[6] = strconv.FormatBool(r.SMTP.Deliverable)
+		record[7] = strconv.FormatBool(r.SMTP.CatchAll)
+	}
+	record[8] = r.Suggestion
+	record[9] = r.Error
+	return record
+}
@@ -0,0 +1,47 @@
+package emailverifier
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteResultsCSV(t *testing.T) {
+	results := []*Result{
+		{
+			Email:        "user@example.org",
+			Reachable:    reachableYes,
+			HasMxRecords: true,
+			Free:         true,
+			SMTP:         &SMTP{HostExists: true, Deliverable: true},
+		},
+		{
+			Email:       "info@gmial.com",
+			Reachable:   reachableUnknown,
+			RoleAccount: true,
+			Suggestion:  "gmail.com",
+			Error:       "lookup failed, \"timeout\"",
+		},
+		nil,
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteResultsCSV(&buf, results))
+	assert.Equal(t, "email,reachable,has_mx_records,disposable,role_account,free,smtp_deliverable,smtp_catch_all,suggestion,error\n"+
+		"user@example.org,yes,true,false,false,true,true,false,,\n"+
+		"info@gmial.com,unknown,false,false,true,false,,,gmail.com,\"lookup failed, \"\"timeout\"\"\"\n"+
+		",,,,,,,,,\n", buf.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteResultsCSV_WriteError(t *testing.T) {
+	err := WriteResultsCSV(failingWriter{}, []*Result{{Email: "user@example.org"}})
+	assert.EqualError(t, err, "disk full")
+}
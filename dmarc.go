@@ -0,0 +1,113 @@
+package emailverifier
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ErrDMARCNotFound is returned by CheckDMARC when the domain publishes no DMARC record
+var ErrDMARCNotFound = errors.New("DMARC record not found")
+
+// DMARC is detail about the DMARC policy of a domain
+type DMARC struct {
+	Record           string   `json:"record"`            // the raw DMARC record
+	Policy           string   `json:"policy"`            // p=, one of none, quarantine or reject
+	SubdomainPolicy  string   `json:"subdomain_policy"`  // sp=, defaults to the policy
+	Percentage       int      `json:"percentage"`        // pct=, defaults to 100
+	AggregateReports []string `json:"aggregate_reports"` // rua= reporting addresses
+	ForensicReports  []string `json:"forensic_reports"`  // ruf= reporting addresses
+}
+
+// CheckDMARC queries the _dmarc.<domain> TXT records and parses the DMARC policy,
+// ErrDMARCNotFound is returned when the domain publishes no DMARC record
+func (v *Verifier) CheckDMARC(domain string) (*DMARC, error) {
+	return v.snapshot().checkDMARC(domain)
+}
+
+// checkDMARC queries and parses the DMARC policy of the domain, see CheckDMARC
+func (v *Verifier) checkDMARC(domain string) (*DMARC, error) {
+	domain = DomainToASCII(domain)
+	if dmarc, ok := v.domainCache.dmarc(domain); ok {
+		if dmarc == nil {
+			return nil, ErrDMARCNotFound
+		}
+		return dmarc, nil
+	}
+
+	dmarc, err := v.lookupDMARC(domain)
+	if err == nil || err == ErrDMARCNotFound {
+		v.domainCache.setDMARC(domain, dmarc)
+	}
+	return dmarc, err
+}
+
+// lookupDMARC looks up the DMARC policy of the domain
+func (v *Verifier) lookupDMARC(domain string) (*DMARC, error) {
+	records, err := v.lookupTXT("_dmarc." + domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, ErrDMARCNotFound
+		}
+		return nil, err
+	}
+
+	for _, r := range records {
+		if dmarc, ok := parseDMARC(r); ok {
+			return dmarc, nil
+		}
+	}
+	return nil, ErrDMARCNotFound
+}
+
+// parseDMARC parses the DMARC record, it returns false when the record is not a DMARC one
+func parseDMARC(record string) (*DMARC, bool) {
+	tags := strings.Split(record, ";")
+	if !strings.EqualFold(strings.Replace(strings.TrimSpace(tags[0]), " ", "", -1), "v=DMARC1") {
+		return nil, false
+	}
+
+	dmarc := DMARC{
+		Record:     record,
+		Percentage: 100,
+	}
+	for _, tag := range tags[1:] {
+		kv := strings.SplitN(tag, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(kv[1])
+		switch strings.ToLower(strings.TrimSpace(kv[0])) {
+		case "p":
+			dmarc.Policy = strings.ToLower(value)
+		case "sp":
+			dmarc.SubdomainPolicy = strings.ToLower(value)
+		case "pct":
+			if pct, err := strconv.Atoi(value); err == nil {
+				dmarc.Percentage = pct
+			}
+		case "rua":
+			dmarc.AggregateReports = splitDMARCURIs(value)
+		case "ruf":
+			dmarc.ForensicReports = splitDMARCURIs(value)
+		}
+	}
+	if dmarc.SubdomainPolicy == "" {
+		dmarc.SubdomainPolicy = dmarc.Policy
+	}
+
+	return &dmarc, true
+}
+
+// splitDMARCURIs splits the comma separated reporting URIs
+func splitDMARCURIs(value string) []string {
+	var uris []string
+	for _, u := range strings.Split(value, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			uris = append(uris, u)
+		}
+	}
+	return uris
+}
@@ -0,0 +1,59 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDMARCOK(t *testing.T) {
+	record := "v=DMARC1; p=Reject; pct=50; rua=mailto:dmarc@example.com,mailto:agg@example.org; ruf=mailto:forensic@example.com"
+
+	dmarc, ok := parseDMARC(record)
+	assert.True(t, ok)
+	assert.Equal(t, &DMARC{
+		Record:           record,
+		Policy:           "reject",
+		SubdomainPolicy:  "reject",
+		Percentage:       50,
+		AggregateReports: []string{"mailto:dmarc@example.com", "mailto:agg@example.org"},
+		ForensicReports:  []string{"mailto:forensic@example.com"},
+	}, dmarc)
+}
+
+func TestParseDMARCOK_SubdomainPolicy(t *testing.T) {
+	dmarc, ok := parseDMARC("v=DMARC1;p=none;sp=quarantine")
+	assert.True(t, ok)
+	assert.Equal(t, "none", dmarc.Policy)
+	assert.Equal(t, "quarantine", dmarc.SubdomainPolicy)
+	assert.Equal(t, 100, dmarc.Percentage)
+}
+
+func TestParseDMARCFailed_NotDMARC(t *testing.T) {
+	_, ok := parseDMARC("v=spf1 include:_spf.example.com ~all")
+	assert.False(t, ok)
+}
+
+func TestCheckDMARCOK(t *testing.T) {
+	resolver := &mockResolver{txt: map[string][]string{
+		"_dmarc.example.com": {"google-site-verification=abc", "v=DMARC1; p=quarantine"},
+	}}
+	verifier := NewVerifier().EnableMXResolver(resolver)
+
+	dmarc, err := verifier.CheckDMARC("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "quarantine", dmarc.Policy)
+}
+
+func TestCheckDMARCFailed_NotFound(t *testing.T) {
+	resolver := &mockResolver{txt: map[string][]string{
+		"_dmarc.example.org": {"google-site-verification=abc"},
+	}}
+	verifier := NewVerifier().EnableMXResolver(resolver)
+
+	_, err := verifier.CheckDMARC("example.com")
+	assert.Equal(t, ErrDMARCNotFound, err)
+
+	_, err = verifier.CheckDMARC("example.org")
+	assert.Equal(t, ErrDMARCNotFound, err)
+}
@@ -0,0 +1,88 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDomainAgeNotFound is returned by CheckDomainAge when the registration date of the domain is not known
+var ErrDomainAgeNotFound = errors.New("domain registration date not found")
+
+// DomainAge is detail about the registration of a domain
+type DomainAge struct {
+	CreatedAt time.Time `json:"created_at"` // registration date of the domain
+	Days      int       `json:"days"`       // number of whole days since the registration
+}
+
+// CheckDomainAge returns the registration date of the domain, looked up via RDAP.
+// The dates are cached per domain, ErrDomainAgeNotFound is returned when the date is not known,
+// ErrRDAPNotSupported when the registry of the domain offers no RDAP service.
+func (v *Verifier) CheckDomainAge(domain string) (time.Time, error) {
+	domain = strings.ToLower(DomainToASCII(domain))
+	if created, ok := v.domainAges.get(domain); ok {
+		return created, nil
+	}
+
+	ctx, cancel := context.WithTimeout(v.context(), 10*time.Second)
+	defer cancel()
+	rdap, err := v.queryRDAP(ctx, domain)
+	if err == ErrRDAPNotFound {
+		return time.Time{}, ErrDomainAgeNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	created, ok := rdap.event("registration")
+	if !ok {
+		return time.Time{}, ErrDomainAgeNotFound
+	}
+	v.domainAges.set(domain, created)
+	return created, nil
+}
+
+// checkDomainAge returns the DomainAge of the domain for the Result
+func (v *Verifier) checkDomainAge(domain string) (*DomainAge, error) {
+	created, err := v.CheckDomainAge(domain)
+	if err != nil {
+		return nil, err
+	}
+	return &DomainAge{
+		CreatedAt: created,
+		Days:      int(time.Since(created) / (24 * time.Hour)),
+	}, nil
+}
+
+// domainAgeCache is a goroutine-safe cache of the registration dates keyed by domain,
+// the dates never change, so the entries do not expire
+type domainAgeCache struct {
+	mu    sync.RWMutex
+	dates map[string]time.Time
+}
+
+func newDomainAgeCache() *domainAgeCache {
+	return &domainAgeCache{dates: map[string]time.Time{}}
+}
+
+func (c *domainAgeCache) get(domain string) (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	created, ok := c.dates[domain]
+	return created, ok
+}
+
+func (c *domainAgeCache) set(domain string, created time.Time) {
+	c.mu.Lock()
+	c.dates[domain] = created
+	c.mu.Unlock()
+}
+
+// clear removes all the cached dates
+func (c *domainAgeCache) clear() {
+	c.mu.Lock()
+	c.dates = map[string]time.Time{}
+	c.mu.Unlock()
+}
@@ -0,0 +1,76 @@
+package emailverifier
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestCheckDomainAge(t *testing.T) {
+	t.Run("registered", func(tt *testing.T) {
+		defer gock.Off()
+		mockRDAPBootstrap()
+		gock.New("https://rdap.verisign.com").
+			Get("/com/v1/domain/example.com").
+			Times(1).
+			Reply(http.StatusOK).
+			BodyString(`{"objectClassName":"domain","ldhName":"EXAMPLE.COM","events":[` +
+				`{"eventAction":"expiration","eventDate":"2030-08-13T04:00:00Z"},` +
+				`{"eventAction":"registration","eventDate":"1995-08-14T04:00:00Z"}]}`)
+
+		v := NewVerifier()
+		created, err := v.CheckDomainAge("Example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(1995, 8, 14, 4, 0, 0, 0, time.UTC), created.UTC())
+		assert.True(t, gock.IsDone())
+
+		// served from the cache, the mocks above match once only
+		created, err = v.CheckDomainAge("example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(1995, 8, 14, 4, 0, 0, 0, time.UTC), created.UTC())
+	})
+	t.Run("no registration event", func(tt *testing.T) {
+		defer gock.Off()
+		mockRDAPBootstrap()
+		gock.New("https://rdap.verisign.com").
+			Get("/com/v1/domain/example.net").
+			Reply(http.StatusOK).
+			BodyString(`{"objectClassName":"domain","events":[]}`)
+
+		_, err := NewVerifier().CheckDomainAge("example.net")
+		assert.Equal(t, ErrDomainAgeNotFound, err)
+	})
+	t.Run("not found", func(tt *testing.T) {
+		defer gock.Off()
+		mockRDAPBootstrap()
+		gock.New("https://rdap.verisign.com").
+			Get("/com/v1/domain/unregistered.com").
+			Reply(http.StatusNotFound)
+
+		_, err := NewVerifier().CheckDomainAge("unregistered.com")
+		assert.Equal(t, ErrDomainAgeNotFound, err)
+	})
+	t.Run("server error", func(tt *testing.T) {
+		defer gock.Off()
+		mockRDAPBootstrap()
+		gock.New("https://rdap.publicinterestregistry.org").
+			Get("/rdap/domain/example.org").
+			Reply(http.StatusServiceUnavailable)
+
+		_, err := NewVerifier().CheckDomainAge("example.org")
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrDomainAgeNotFound, err)
+	})
+}
+
+func TestCheckDomainAge_Days(t *testing.T) {
+	v := NewVerifier()
+	v.domainAges.set("fresh.example", time.Now().Add(-50*time.Hour))
+
+	age, err := v.checkDomainAge("fresh.example")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, age.Days)
+}
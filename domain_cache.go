@@ -0,0 +1,183 @@
+package emailverifier
+
+import (
+	"sync"
+	"time"
+)
+
+// The properties of a domain kept by the domain cache
+const (
+	domainCatchAll     = "catch_all"
+	domainDMARC        = "dmarc"
+	domainParked       = "parked"
+	domainMailServices = "mail_services"
+)
+
+// domainCache is a goroutine-safe in-memory cache of the properties of the domains, which do not change
+// per address, i.e. the catch-all status, the DMARC policy and the other domain checks. The MX records
+// are kept by the MX cache, which is independent of it.
+// Its methods are no-ops on a nil cache, i.e. when it is disabled.
+type domainCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration // maximum lifetime of an entry
+	entries map[domainCacheKey]domainCacheEntry
+}
+
+type domainCacheKey struct {
+	domain   string
+	property string
+}
+
+type domainCacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// newDomainCache creates a new domain cache whose entries live at most ttl
+func newDomainCache(ttl time.Duration) *domainCache {
+	return &domainCache{
+		ttl:     ttl,
+		entries: map[domainCacheKey]domainCacheEntry{},
+	}
+}
+
+// get returns the cached property of the domain, if it has not expired yet
+func (c *domainCache) get(domain, property string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key := domainCacheKey{domain, property}
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(e.expires) {
+		c.mu.Lock()
+		if e, ok = c.entries[key]; ok && time.Now().After(e.expires) {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		return nil, false
+	}
+	return e.value, true
+}
+
+// set stores the property of the domain, ttl bounds the lifetime of the entry
+// when it is positive and shorter than the configured TTL
+func (c *domainCache) set(domain, property string, value interface{}, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+	if ttl <= 0 || ttl > c.ttl {
+		ttl = c.ttl
+	}
+
+	c.mu.Lock()
+	c.entries[domainCacheKey{domain, property}] = domainCacheEntry{
+		value:   value,
+		expires: time.Now().Add(ttl),
+	}
+	c.mu.Unlock()
+}
+
+// clear removes all the cached entries
+func (c *domainCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.entries = map[domainCacheKey]domainCacheEntry{}
+	c.mu.Unlock()
+}
+
+// catchAll returns the cached outcome of the catch-all probe of the domain
+func (c *domainCache) catchAll(domain string) (catchAllProbe, bool) {
+	v, ok := c.get(domain, domainCatchAll)
+	if !ok {
+		return catchAllProbe{}, false
+	}
+	return v.(catchAllProbe), true
+}
+
+// setCatchAll stores the outcome of the catch-all probe of the domain, when it is definite
+func (c *domainCache) setCatchAll(domain string, ret *SMTP) {
+	if ret.CatchAllStatus != CatchAllYes && ret.CatchAllStatus != CatchAllNo {
+		return
+	}
+	c.set(domain, domainCatchAll, catchAllProbe{status: ret.CatchAllStatus, code: ret.LastStatusCode, response: ret.LastResponse}, 0)
+}
+
+// dmarc returns the cached DMARC policy of the domain, nil when the domain publishes none
+func (c *domainCache) dmarc(domain string) (*DMARC, bool) {
+	v, ok := c.get(domain, domainDMARC)
+	if !ok {
+		return nil, false
+	}
+	return v.(*DMARC), true
+}
+
+// setDMARC stores the DMARC policy of the domain, nil when the domain publishes none
+func (c *domainCache) setDMARC(domain string, dmarc *DMARC) {
+	c.set(domain, domainDMARC, dmarc, 0)
+}
+
+// parked returns whether the domain is cached as parked
+func (c *domainCache) parked(domain string) (bool, bool) {
+	v, ok := c.get(domain, domainParked)
+	if !ok {
+		return false, false
+	}
+	return v.(bool), true
+}
+
+// setParked stores whether the domain is parked
+func (c *domainCache) setParked(domain string, parked bool) {
+	c.set(domain, domainParked, parked, 0)
+}
+
+// mailServices returns the cached mail access services of the domain
+func (c *domainCache) mailServices(domain string) (*MailServices, bool) {
+	v, ok := c.get(domain, domainMailServices)
+	if !ok {
+		return nil, false
+	}
+	return v.(*MailServices), true
+}
+
+// setMailServices stores the mail access services of the domain
+func (c *domainCache) setMailServices(domain string, services *MailServices) {
+	c.set(domain, domainMailServices, services, 0)
+}
+
+// EnableDomainCache caches the properties of the domains, which do not change per address, for at most ttl:
+// the outcome of the catch-all probe, the DMARC policy, the parked status and the mail access services.
+// Verifying many addresses at the same domain then repeats none of these checks. Unlike the result cache,
+// see EnableResultCache, it serves every address at the domain. The MX records are cached by the MX cache,
+// enable it as well via EnableMXCache.
+func (v *Verifier) EnableDomainCache(ttl time.Duration) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.domainCache = newDomainCache(ttl)
+	return v
+}
+
+// DisableDomainCache disables the domain cache (default)
+func (v *Verifier) DisableDomainCache() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.domainCache = nil
+	return v
+}
+
+// ClearDomainCache removes all the cached properties of the domains, the MX records are removed by ClearMXCache
+func (v *Verifier) ClearDomainCache() {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	v.domainCache.clear()
+}
@@ -0,0 +1,121 @@
+package emailverifier
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainCacheOK_GetSet(t *testing.T) {
+	c := newDomainCache(time.Minute)
+
+	_, ok := c.parked("example.com")
+	assert.False(t, ok)
+
+	c.setParked("example.com", true)
+	parked, ok := c.parked("example.com")
+	assert.True(t, ok)
+	assert.True(t, parked)
+	_, ok = c.dmarc("example.com")
+	assert.False(t, ok)
+
+	c.clear()
+	_, ok = c.parked("example.com")
+	assert.False(t, ok)
+}
+
+func TestDomainCacheOK_Expired(t *testing.T) {
+	c := newDomainCache(time.Hour)
+	c.setDMARC("example.com", nil)
+	c.set("example.com", domainParked, true, time.Millisecond)
+
+	time.Sleep(5 * time.Millisecond)
+	_, ok := c.parked("example.com")
+	assert.False(t, ok)
+	_, ok = c.dmarc("example.com")
+	assert.True(t, ok)
+}
+
+func TestEnableDomainCache_MXCacheIndependent(t *testing.T) {
+	assert.Nil(t, NewVerifier().EnableDomainCache(time.Hour).mxCache)
+
+	v := NewVerifier().EnableMXCache(time.Minute).EnableDomainCache(time.Hour)
+	v.mxCache.set("example.com", []*net.MX{{Host: "mx.example.com."}}, 0)
+	v.ClearDomainCache()
+	_, ok := v.mxCache.get("example.com")
+	assert.True(t, ok)
+
+	v.DisableDomainCache()
+	assert.NotNil(t, v.mxCache)
+	assert.Equal(t, time.Minute, v.mxCache.ttl)
+}
+
+func TestDomainCacheOK_CatchAllDefiniteOnly(t *testing.T) {
+	c := newDomainCache(time.Minute)
+	c.setCatchAll("inconclusive.com", &SMTP{CatchAllStatus: CatchAllInconclusive})
+	c.setCatchAll("example.com", &SMTP{CatchAllStatus: CatchAllNo, LastStatusCode: 550, LastResponse: "user unknown"})
+
+	_, ok := c.catchAll("inconclusive.com")
+	assert.False(t, ok)
+	probe, ok := c.catchAll("example.com")
+	assert.True(t, ok)
+	assert.Equal(t, catchAllProbe{status: CatchAllNo, code: 550, response: "user unknown"}, probe)
+}
+
+func TestDomainCacheOK_Disabled(t *testing.T) {
+	var c *domainCache
+	c.setParked("example.com", true)
+	_, ok := c.parked("example.com")
+	assert.False(t, ok)
+	c.clear()
+}
+
+func TestVerify_DomainCache(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("john@example.test", "jane@example.test")
+	obs := &recordingObserver{}
+	verifier := NewVerifier().
+		EnableSMTPCheck().
+		EnableDMARCCheck().
+		EnableDomainCache(time.Minute).
+		EnableMXCache(time.Minute).
+		EnableCustomDialer(srv.dialer()).
+		SetObserver(obs).
+		EnableMXResolver(&mockResolver{
+			mx:  map[string][]*net.MX{"example.test": {{Host: "mx.example.test.", Pref: 10}}},
+			txt: map[string][]string{"_dmarc.example.test": {"v=DMARC1; p=reject"}},
+		})
+
+	for _, email := range []string{"john@example.test", "jane@example.test"} {
+		ret, err := verifier.Verify(email)
+		assert.NoError(t, err)
+		assert.Equal(t, reachableYes, ret.Reachable)
+		assert.Equal(t, CatchAllNo, ret.SMTP.CatchAllStatus)
+		assert.Equal(t, "reject", ret.DMARC.Policy)
+	}
+
+	// The domain is resolved and probed for the catch-all once, the addresses are checked each
+	assert.Equal(t, []string{"mx example.test"}, filterEvents(obs.events, "mx "))
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 3)
+	_, ok := verifier.domainCache.dmarc("example.test")
+	assert.True(t, ok)
+
+	verifier.ClearDomainCache()
+	_, err := verifier.Verify("john@example.test")
+	assert.NoError(t, err)
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 5)
+}
+
+// filterEvents returns the events of the observer starting with the prefix
+func filterEvents(events []string, prefix string) []string {
+	var ret []string
+	for _, e := range events {
+		if strings.HasPrefix(e, prefix) {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
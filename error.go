@@ -10,15 +10,19 @@ const (
 	// Standard Errors
 	ErrTimeout           = "The connection to the mail server has timed out"
 	ErrNoSuchHost        = "Mail server does not exist"
+	ErrNoMXRecords       = "Domain has no MX records"
 	ErrServerUnavailable = "Mail server is unavailable"
 	ErrBlocked           = "Blocked by mail server"
 
+	ErrSMTPUTF8Unsupported = "Mail server does not support SMTPUTF8"
+
 	// RCPT Errors
 	ErrTryAgainLater           = "Try again later"
 	ErrFullInbox               = "Recipient out of disk space"
 	ErrTooManyRCPT             = "Too many recipients"
 	ErrNoRelay                 = "Not an open relay"
 	ErrMailboxBusy             = "Mailbox busy"
+	ErrGreylisted              = "Greylisted, try again later"
 	ErrExceededMessagingLimits = "Messaging limits have been exceeded"
 	ErrNotAllowed              = "Not Allowed"
 	ErrNeedMAILBeforeRCPT      = "Need MAIL before RCPT"
@@ -41,6 +45,32 @@ func (e *LookupError) Error() string {
 	return fmt.Sprintf("%s : %s", e.Message, e.Details)
 }
 
+// The errors of the Verify stages, wrapping the underlying error. Use errors.As to find out
+// the stage that failed, e.g. to retry the SMTP check only.
+type (
+	// MXError is returned by Verify when the MX lookup fails
+	MXError struct{ Err error }
+	// SMTPError is returned by Verify when the SMTP check fails
+	SMTPError struct{ Err error }
+	// GravatarError is returned by Verify when the gravatar check fails
+	GravatarError struct{ Err error }
+	// DMARCError is returned by Verify when the DMARC lookup fails
+	DMARCError struct{ Err error }
+	// DomainAgeError is returned by Verify when the domain age lookup fails
+	DomainAgeError struct{ Err error }
+)
+
+func (e *MXError) Error() string        { return e.Err.Error() }
+func (e *MXError) Unwrap() error        { return e.Err }
+func (e *SMTPError) Error() string      { return e.Err.Error() }
+func (e *SMTPError) Unwrap() error      { return e.Err }
+func (e *GravatarError) Error() string  { return e.Err.Error() }
+func (e *GravatarError) Unwrap() error  { return e.Err }
+func (e *DMARCError) Error() string     { return e.Err.Error() }
+func (e *DMARCError) Unwrap() error     { return e.Err }
+func (e *DomainAgeError) Error() string { return e.Err.Error() }
+func (e *DomainAgeError) Unwrap() error { return e.Err }
+
 // ParseSMTPError receives an MX Servers response message
 // and generates the corresponding MX error
 func ParseSMTPError(err error) *LookupError {
@@ -59,6 +89,12 @@ func ParseSMTPError(err error) *LookupError {
 
 	// If the status code is above 400 there was an error and we should return it
 	if status > 400 {
+		// Greylisting servers temporarily reject unknown senders, the phrasing often
+		// mentions the recipient address, so it must be detected before the undeliverable one
+		if (status == 450 || status == 451) && isGreylisting(errStr) {
+			return newLookupError(status, ErrGreylisted, errStr)
+		}
+
 		// Don't return an error if the error contains anything about the address
 		// being undeliverable
 		if insContains(errStr,
@@ -75,6 +111,18 @@ func ParseSMTPError(err error) *LookupError {
 			return newLookupError(status, ErrServerUnavailable, errStr)
 		}
 
+		// The permanent failures of the big providers, whose phrasing the lists above miss
+		if status >= 500 {
+			switch {
+			case isSenderBlocked(errStr):
+				return newLookupError(status, ErrBlocked, errStr)
+			case isRecipientDisabled(errStr):
+				return newLookupError(status, ErrNotAllowed, errStr)
+			case isRecipientUnknown(errStr):
+				return newLookupError(status, ErrServerUnavailable, errStr)
+			}
+		}
+
 		switch status {
 		case 421:
 			return newLookupError(status, ErrTryAgainLater, errStr)
@@ -148,6 +196,76 @@ func parseBasicErr(status int, err error) *LookupError {
 	}
 }
 
+// isGreylisting returns true if the temporary failure message names the greylisting, e.g.
+// "Greylisted, please try again later". The generic deferrals, e.g. of a busy mailbox or
+// a rate limit, are left to the mapping of their status code.
+func isGreylisting(errStr string) bool {
+	return insContains(errStr,
+		"greylist",
+		"graylist",
+		"grey-list",
+		"gray-list",
+		"grey list",
+		"gray list")
+}
+
+// isSenderBlocked returns true if the permanent failure message blames the reputation
+// of the sending IP rather than the recipient, e.g. its listing on a block list
+func isSenderBlocked(errStr string) bool {
+	return insContains(errStr,
+		"spamhaus",
+		"proofpoint",
+		"cloudmark",
+		"barracuda",
+		"spamcop",
+		"sorbs",
+		"block list",
+		"blocklist",
+		"blacklist",
+		"banned sending ip",
+		"banned sender",
+		"unsolicited mail",
+		"poor reputation",
+		"ip reputation",
+		"5.7.25",
+		"ptr record")
+}
+
+// isRecipientDisabled returns true if the permanent failure message reports
+// the recipient account exists but is disabled
+func isRecipientDisabled(errStr string) bool {
+	return insContains(errStr,
+		"account that you tried to reach is disabled",
+		"account is disabled",
+		"account has been disabled",
+		"mailbox is disabled",
+		"mailbox disabled",
+		"account is inactive")
+}
+
+// isRecipientUnknown returns true if the permanent failure message reports the recipient
+// does not exist, in the phrasing of Microsoft, Google, Yahoo and other providers
+func isRecipientUnknown(errStr string) bool {
+	return insContains(errStr,
+		"mailbox unavailable",
+		"mailbox not found",
+		"recipientnotfound",
+		"recipnotfound",
+		"recipient not found",
+		"recipient unknown",
+		"unknown recipient",
+		"unknown user",
+		"no such user",
+		"no such recipient",
+		"not a valid recipient",
+		"unrouteable address",
+		"address not found",
+		"doesn't have a yahoo.com account",
+		"doesn't have a ymail.com account",
+		"doesn't have a rocketmail.com account",
+		"doesn't have a aol.com account")
+}
+
 // insContains returns true if any of the substrings
 // are found in the passed string. This method of checking
 // contains is case insensitive
@@ -174,3 +174,77 @@ func TestParseError_basicErr_blocked(t *testing.T) {
 	assert.Equal(t, ErrBlocked, le.Message)
 	assert.Equal(t, err.Error(), le.Details)
 }
+
+func TestParseError_Greylisted(t *testing.T) {
+	cases := []string{
+		"450 4.2.0 <user@example.com>: Recipient address rejected: Greylisted, see https://postgrey.schweikert.ch/",
+		"451 4.7.1 Greylisted, please try again later",
+		"451 4.7.1 Deferred due to greylisting",
+	}
+	for _, errStr := range cases {
+		le := ParseSMTPError(errors.New(errStr))
+		assert.Equal(t, ErrGreylisted, le.Message, errStr)
+		assert.Equal(t, errStr, le.Details)
+	}
+}
+
+func TestParseError_NotGreylisted(t *testing.T) {
+	cases := []struct {
+		errStr  string
+		message string
+	}{
+		{"451 4.7.1 Please try again later", ErrExceededMessagingLimits},
+		{"451 Temporary local problem - please try later", ErrExceededMessagingLimits},
+		{"450 4.2.1 The user you are trying to contact is receiving mail too quickly, deferred", ErrMailboxBusy},
+	}
+	for _, c := range cases {
+		le := ParseSMTPError(errors.New(c.errStr))
+		assert.Equal(t, c.message, le.Message, c.errStr)
+	}
+}
+
+func TestParseError_ProviderBounces(t *testing.T) {
+	cases := []struct {
+		errStr  string
+		message string
+	}{
+		// Microsoft
+		{"550 5.5.0 Requested action not taken: mailbox unavailable (S2017062302). [AM4EUR05FT023.eop-eur05.prod.protection.outlook.com]", ErrServerUnavailable},
+		{"550 5.1.10 RESOLVER.ADR.RecipientNotFound; Recipient not found by SMTP address lookup", ErrServerUnavailable},
+		{"554 5.1.1 RESOLVER.ADR.ExRecipNotFound; not found", ErrServerUnavailable},
+		{"550 5.7.1 Unfortunately, messages from [192.0.2.1] weren't sent. Please contact your Internet service provider since part of their network is on our block list (S3150).", ErrBlocked},
+		{"554 5.7.606 Access denied, banned sending IP [192.0.2.1]", ErrBlocked},
+		// Google
+		{"550-5.1.1 The email account that you tried to reach does not exist. Please try\n5.1.1 double-checking the recipient's email address for typos or\n5.1.1 unnecessary spaces. Learn more at\n5.1.1  https://support.google.com/mail/?p=NoSuchUser", ErrServerUnavailable},
+		{"550-5.2.1 The email account that you tried to reach is disabled. Learn more at\n5.2.1  https://support.google.com/mail/?p=DisabledUser", ErrNotAllowed},
+		{"550-5.7.1 [192.0.2.1] Our system has detected that this message is\n5.7.1 likely unsolicited mail. To reduce the amount of spam sent to Gmail,", ErrBlocked},
+		{"550-5.7.25 [192.0.2.1] The IP address sending this message does not have a PTR record setup, or the\n5.7.25 corresponding forward DNS entry does not point to the sending IP.", ErrBlocked},
+		// Yahoo
+		{"554 delivery error: dd This user doesn't have a yahoo.com account (someone@yahoo.com) [0] - mta1234.mail.gq1.yahoo.com", ErrServerUnavailable},
+		{"554 delivery error: dd This user doesn't have a aol.com account (someone@aol.com) [0] - mta1234.mail.gq1.yahoo.com", ErrServerUnavailable},
+		// Sender-side rejections phrased like the unknown recipients
+		{"554 5.7.25 The sending host does not have a PTR record", ErrBlocked},
+		{"554 5.7.1 The sender does not have a valid SPF record", ErrNotAllowed},
+		// Block lists rejecting with other codes than 550
+		{"554 5.7.1 Service unavailable; Client host [192.0.2.1] blocked using zen.spamhaus.org", ErrBlocked},
+		{"553 5.7.1 Sender IP listed on Barracuda reputation block list", ErrBlocked},
+		// Generic phrasing
+		{"550 5.1.1 <someone@example.com>: unknown user", ErrServerUnavailable},
+		{"551 No such user here", ErrServerUnavailable},
+		{"554 5.7.1 <someone@example.com>: Relay access denied", ErrNotAllowed},
+		// The temporary failures are not affected
+		{"450 4.2.1 Requested mail action not taken: mailbox unavailable, try again", ErrMailboxBusy},
+	}
+	for _, c := range cases {
+		le := ParseSMTPError(errors.New(c.errStr))
+		assert.Equal(t, c.message, le.Message, c.errStr)
+		assert.Equal(t, c.errStr, le.Details)
+	}
+}
+
+func TestParseError_PTRBounceNotRecipientUnknown(t *testing.T) {
+	errStr := "550-5.7.25 [192.0.2.1] The IP address sending this message does not have a PTR record setup, or the\n5.7.25 corresponding forward DNS entry does not point to the sending IP."
+	assert.False(t, isRecipientUnknown(errStr))
+	assert.True(t, isSenderBlocked(errStr))
+	assert.NotEqual(t, ErrServerUnavailable, ParseSMTPError(errors.New(errStr)).Message)
+}
@@ -0,0 +1,41 @@
+package emailverifier
+
+import "strings"
+
+// AddGatewayMXHosts marks the MX hosts, and all the hosts under them, as mail security gateways
+// in addition to the built-in ones, e.g. "pphosted.com"
+func (v *Verifier) AddGatewayMXHosts(hosts []string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	normalized := make([]string, len(hosts))
+	for i, h := range hosts {
+		normalized[i] = strings.TrimSuffix(h, ".")
+	}
+	v.gatewayMXHosts.add(normalized)
+	return v
+}
+
+// EnableGatewayDowngrade reports the addresses accepted by a mail security gateway as
+// reachable "unknown" rather than "yes", as the gateways accept any recipient
+func (v *Verifier) EnableGatewayDowngrade() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.gatewayDowngrade = true
+	return v
+}
+
+// DisableGatewayDowngrade reports the addresses accepted by a mail security gateway as reachable
+func (v *Verifier) DisableGatewayDowngrade() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.gatewayDowngrade = false
+	return v
+}
+
+// isGatewayMX checks if the MX host, or any of its parent domains, is a known mail security gateway
+func (v *Verifier) isGatewayMX(host string) bool {
+	return v.gatewayMXHosts.hasHost(host)
+}
@@ -0,0 +1,44 @@
+package emailverifier
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGatewayMX(t *testing.T) {
+	v := NewVerifier().AddGatewayMXHosts([]string{"gateway.example."})
+
+	assert.True(t, v.isGatewayMX("mx0a-001.pphosted.com."))
+	assert.True(t, v.isGatewayMX("EU-SMTP-INBOUND-1.MIMECAST.COM"))
+	assert.True(t, v.isGatewayMX("gateway.example"))
+	assert.True(t, v.isGatewayMX("mx.gateway.example."))
+	assert.False(t, v.isGatewayMX("mx.example.com."))
+	assert.False(t, v.isGatewayMX("pphosted.com.example."))
+	assert.False(t, NewVerifier().isGatewayMX("mx.gateway.example."))
+}
+
+func TestVerify_MailGateway(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("john@example.test")
+	verifier := NewVerifier().
+		EnableSMTPCheck().
+		EnableCustomDialer(srv.dialer()).
+		EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+			"example.test": {{Host: "mx0a-001.pphosted.com.", Pref: 10}},
+		}})
+
+	ret, err := verifier.Verify("john@example.test")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.GatewayDetected)
+	assert.True(t, ret.SMTP.Deliverable)
+	assert.Equal(t, reachableYes, ret.Reachable)
+
+	ret, err = verifier.EnableGatewayDowngrade().Verify("john@example.test")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.GatewayDetected)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Equal(t, ReasonMailGateway, ret.Reason)
+	assert.Equal(t, 65, ret.Score)
+}
@@ -8,6 +8,7 @@ require (
 	github.com/kr/pretty v0.2.1 // indirect
 	github.com/stretchr/testify v1.8.4
 	golang.org/x/net v0.28.0
+	golang.org/x/time v0.3.0
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/h2non/gock.v1 v1.1.2
 )
@@ -2,6 +2,11 @@ package emailverifier
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -10,24 +15,62 @@ import (
 
 // Gravatar is detail about the Gravatar
 type Gravatar struct {
-	HasGravatar bool   // whether has gravatar
-	GravatarUrl string // gravatar url
+	HasGravatar        bool             // whether has gravatar
+	GravatarUrl        string           // gravatar url
+	Hash               string           // MD5 hash of the normalized email identifying it at Gravatar
+	HashSHA256         string           // SHA-256 hash of the normalized email, also accepted by Gravatar
+	Profile            *GravatarProfile // public profile, nil when not fetched or not found
+	ProfileRateLimited bool             // whether the profile was not fetched due to the rate limit of Gravatar
+	ProfileError       string           // why the profile was not fetched for a reason other than the rate limit
+}
+
+// GravatarProfile is the public Gravatar profile of the email
+type GravatarProfile struct {
+	ProfileURL        string            `json:"profileUrl"`
+	PreferredUsername string            `json:"preferredUsername"`
+	ThumbnailURL      string            `json:"thumbnailUrl"`
+	DisplayName       string            `json:"displayName"`
+	AboutMe           string            `json:"aboutMe"`
+	CurrentLocation   string            `json:"currentLocation"`
+	URLs              []GravatarURL     `json:"urls"`
+	Accounts          []GravatarAccount `json:"accounts"`
+}
+
+// GravatarURL is a link listed in the Gravatar profile
+type GravatarURL struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// GravatarAccount is a verified account on another service listed in the Gravatar profile
+type GravatarAccount struct {
+	Domain    string `json:"domain"`
+	Display   string `json:"display"`
+	URL       string `json:"url"`
+	Username  string `json:"username"`
+	Shortname string `json:"shortname"`
 }
 
 // CheckGravatar will return the Gravatar records for the given email.
 func (v *Verifier) CheckGravatar(email string) (*Gravatar, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	return v.snapshot().checkGravatar(email)
+}
+
+// checkGravatar returns the Gravatar records for the given email, see CheckGravatar
+func (v *Verifier) checkGravatar(email string) (*Gravatar, error) {
+	ctx, cancel := context.WithTimeout(v.context(), 10*time.Second)
 	defer cancel()
-	err, emailMd5 := getMD5Hash(strings.ToLower(strings.TrimSpace(email)))
+	normalized := normalizeGravatarEmail(email)
+	err, emailMd5 := getMD5Hash(normalized)
 	if err != nil {
 		return nil, err
 	}
-	gravatarUrl := gravatarBaseUrl + emailMd5 + "?d=404"
+	gravatarUrl := v.gravatarBaseURL + "/avatar/" + emailMd5 + "?d=404"
 	req, err := http.NewRequest("GET", gravatarUrl, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	resp, err := v.httpClient(v.gravatarClient).Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -45,14 +88,72 @@ func (v *Verifier) CheckGravatar(email string) (*Gravatar, error) {
 	if err != nil {
 		return nil, err
 	}
-	if md5Body == gravatarDefaultMd5 || resp.StatusCode != 200 {
-		return &Gravatar{
-			HasGravatar: false,
-			GravatarUrl: "",
-		}, nil
+	sha := sha256.Sum256([]byte(normalized))
+	ret := Gravatar{
+		Hash:       emailMd5,
+		HashSHA256: hex.EncodeToString(sha[:]),
+	}
+	if md5Body != gravatarDefaultMd5 && resp.StatusCode == 200 {
+		ret.HasGravatar = true
+		ret.GravatarUrl = gravatarUrl
+	}
+
+	if v.gravatarProfileEnabled {
+		ret.Profile, err = v.fetchGravatarProfile(ctx, emailMd5)
+		// The failed profile does not discard the avatar found
+		if err == errGravatarRateLimited {
+			ret.ProfileRateLimited = true
+		} else if err != nil {
+			ret.ProfileError = err.Error()
+		}
+	}
+	return &ret, nil
+}
+
+// normalizeGravatarEmail normalizes the email the way Gravatar does before hashing it
+func normalizeGravatarEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// errGravatarRateLimited is returned when Gravatar refuses the request due to its rate limit
+var errGravatarRateLimited = errors.New("gravatar rate limit exceeded")
+
+// fetchGravatarProfile fetches the public profile of the email hash, nil is returned
+// when the email has no profile
+func (v *Verifier) fetchGravatarProfile(ctx context.Context, emailMd5 string) (*GravatarProfile, error) {
+	req, err := http.NewRequest("GET", v.gravatarBaseURL+"/"+emailMd5+".json", nil)
+	if err != nil {
+		return nil, err
+	}
+	// Gravatar refuses the requests without a user agent
+	req.Header.Set("User-Agent", "email-verifier")
+	resp, err := v.httpClient(v.gravatarClient).Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	case http.StatusTooManyRequests:
+		return nil, errGravatarRateLimited
+	default:
+		return nil, fmt.Errorf("unexpected gravatar profile status: %s", resp.Status)
+	}
+
+	var profile struct {
+		Entry []GravatarProfile `json:"entry"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		return nil, fmt.Errorf("decode gravatar profile: %w", err)
+	}
+	if len(profile.Entry) == 0 {
+		return nil, nil
 	}
-	return &Gravatar{
-		HasGravatar: true,
-		GravatarUrl: gravatarUrl,
-	}, nil
+	return &profile.Entry[0], nil
 }
@@ -1,9 +1,12 @@
 package emailverifier
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
 )
 
 func TestCheckGravatarOK(t *testing.T) {
@@ -23,3 +26,128 @@ func TestCheckGravatarFailed(t *testing.T) {
 	assert.False(t, gravatar.HasGravatar)
 	assert.Empty(t, gravatar.GravatarUrl)
 }
+
+func TestCheckGravatarProfile(t *testing.T) {
+	v := NewVerifier().EnableGravatarProfile()
+	email := " Someone@Example.com "
+	_, hash := getMD5Hash("someone@example.com")
+
+	mockAvatar := func() {
+		gock.New("https://www.gravatar.com").
+			Get("/avatar/" + hash).
+			Reply(http.StatusOK).
+			BodyString("avatar")
+	}
+
+	t.Run("profile found", func(tt *testing.T) {
+		defer gock.Off()
+		mockAvatar()
+		gock.New("https://www.gravatar.com").
+			Get("/" + hash + ".json").
+			Reply(http.StatusOK).
+			BodyString(`{"entry":[{"displayName":"Someone","profileUrl":"https://gravatar.com/someone",` +
+				`"urls":[{"title":"Blog","value":"https://someone.example"}],` +
+				`"accounts":[{"domain":"github.com","username":"someone","shortname":"github"}]}]}`)
+
+		gravatar, err := v.CheckGravatar(email)
+		assert.NoError(t, err)
+		assert.True(t, gravatar.HasGravatar)
+		assert.Equal(t, &GravatarProfile{
+			DisplayName: "Someone",
+			ProfileURL:  "https://gravatar.com/someone",
+			URLs:        []GravatarURL{{Title: "Blog", Value: "https://someone.example"}},
+			Accounts:    []GravatarAccount{{Domain: "github.com", Username: "someone", Shortname: "github"}},
+		}, gravatar.Profile)
+	})
+	t.Run("profile not found", func(tt *testing.T) {
+		defer gock.Off()
+		mockAvatar()
+		gock.New("https://www.gravatar.com").
+			Get("/" + hash + ".json").
+			Reply(http.StatusNotFound).
+			BodyString("User not found")
+
+		gravatar, err := v.CheckGravatar(email)
+		assert.NoError(t, err)
+		assert.True(t, gravatar.HasGravatar)
+		assert.Nil(t, gravatar.Profile)
+		assert.False(t, gravatar.ProfileRateLimited)
+	})
+	t.Run("rate limited", func(tt *testing.T) {
+		defer gock.Off()
+		mockAvatar()
+		gock.New("https://www.gravatar.com").
+			Get("/" + hash + ".json").
+			Reply(http.StatusTooManyRequests)
+
+		gravatar, err := v.CheckGravatar(email)
+		assert.NoError(t, err)
+		assert.True(t, gravatar.HasGravatar)
+		assert.Nil(t, gravatar.Profile)
+		assert.True(t, gravatar.ProfileRateLimited)
+	})
+	t.Run("unexpected status", func(tt *testing.T) {
+		defer gock.Off()
+		mockAvatar()
+		gock.New("https://www.gravatar.com").
+			Get("/" + hash + ".json").
+			Reply(http.StatusInternalServerError)
+
+		gravatar, err := v.CheckGravatar(email)
+		assert.NoError(t, err)
+		assert.True(t, gravatar.HasGravatar)
+		assert.Nil(t, gravatar.Profile)
+		assert.False(t, gravatar.ProfileRateLimited)
+		assert.NotEmpty(t, gravatar.ProfileError)
+	})
+}
+
+func TestCheckGravatar_CustomClientAndBaseURL(t *testing.T) {
+	_, hash := getMD5Hash("someone@example.com")
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/avatar/" + hash:
+			_, _ = w.Write([]byte("avatar"))
+		case "/" + hash + ".json":
+			_, _ = w.Write([]byte(`{"entry":[{"displayName":"Someone"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	v := NewVerifier().
+		EnableGravatarProfile().
+		GravatarHTTPClient(server.Client()).
+		GravatarBaseURL(server.URL + "/")
+
+	gravatar, err := v.CheckGravatar("someone@example.com")
+	assert.NoError(t, err)
+	assert.True(t, gravatar.HasGravatar)
+	assert.Equal(t, server.URL+"/avatar/"+hash+"?d=404", gravatar.GravatarUrl)
+	assert.Equal(t, "Someone", gravatar.Profile.DisplayName)
+	assert.Equal(t, []string{"/avatar/" + hash, "/" + hash + ".json"}, paths)
+}
+
+func TestGravatarDefaults(t *testing.T) {
+	v := NewVerifier().GravatarHTTPClient(&http.Client{}).GravatarBaseURL("http://localhost")
+	v.GravatarHTTPClient(nil).GravatarBaseURL("")
+
+	assert.Equal(t, http.DefaultClient, v.gravatarClient)
+	assert.Equal(t, "https://www.gravatar.com", v.gravatarBaseURL)
+}
+
+func TestCheckGravatar_Hash(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://www.gravatar.com").
+		Get("/avatar/0bc83cb571cd1c50ba6f3e8a78ef1346").
+		Reply(http.StatusNotFound)
+
+	gravatar, err := NewVerifier().CheckGravatar(" MyEmailAddress@example.com ")
+	assert.NoError(t, err)
+	assert.False(t, gravatar.HasGravatar)
+	assert.Equal(t, "0bc83cb571cd1c50ba6f3e8a78ef1346", gravatar.Hash)
+	assert.Equal(t, "84059b07d4be67b806386c0aad8070a23f18836bbaae342275dc0a83414c32ee", gravatar.HashSHA256)
+}
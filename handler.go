@@ -1,49 +1,300 @@
 package emailverifier
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
-// updateDisposableDomains gets domains data from source's URL
-func updateDisposableDomains(source string, updater DisposableRepoUpdater) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// ErrDisposableTooLarge is returned when the response of the source of the disposable domains
+// exceeds the limit, see DisposableMaxSize. The failed fetch is not retried.
+var ErrDisposableTooLarge = errors.New("disposable domains exceed the size limit")
+
+// fetchedDomains keeps the domains of the last update from the source, so the following update
+// removes the domains the source dropped since
+type fetchedDomains struct {
+	mu      sync.Mutex
+	domains map[string]struct{}
+}
+
+// replace keeps the domains of the update and returns the ones dropped since the last update
+func (f *fetchedDomains) replace(domains []string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current := make(map[string]struct{}, len(domains))
+	for _, d := range domains {
+		current[d] = struct{}{}
+	}
+	var dropped []string
+	for d := range f.domains {
+		if _, ok := current[d]; !ok {
+			dropped = append(dropped, d)
+		}
+	}
+	f.domains = current
+	sort.Strings(dropped)
+	return dropped
+}
+
+// disposableUpdater fetches the disposable domains from the source into the repo
+type disposableUpdater struct {
+	source  string
+	repo    DisposableRepoUpdater
+	client  *http.Client  // http.DefaultClient when nil
+	timeout time.Duration // of the whole fetch, defaultDisposableFetchTimeout when zero
+	maxSize int64         // of the response body in bytes, defaultDisposableMaxSize when zero
+	replace bool          // replace the domains of the repo instead of adding to them
+	fetched *fetchedDomains
+
+	attempts int                    // of the fetch in total, failed ones are retried with the doubling backoff
+	backoff  time.Duration          // delay before the first retry
+	onUpdate func(DisposableUpdate) // notified about the outcome of each run, may be nil
+	logger   *slog.Logger           // logs the outcome of each run, may be nil
+	ctx      context.Context        // cancels the fetch and the retries of the run, context.Background() when nil
+}
+
+// DisposableUpdate is the outcome of an automatic update of the disposable domains
+type DisposableUpdate struct {
+	Domains  int       // number of the domains fetched from the source
+	Attempts int       // number of the fetch attempts made
+	Err      error     // error of the last attempt, nil on success
+	At       time.Time // when the update finished
+}
+
+// newDisposableUpdater creates the updater of the disposable domains of the verifier from the source
+func (v *Verifier) newDisposableUpdater(source string) *disposableUpdater {
+	return &disposableUpdater{
+		source:  source,
+		repo:    v.disposableRepo,
+		client:  v.httpClient(v.disposableClient),
+		timeout: v.disposableFetchTimeout,
+		maxSize: v.disposableMaxSize,
+		replace: v.disposableReplace,
+		fetched: &fetchedDomains{},
+
+		attempts: v.disposableFetchAttempts,
+		backoff:  v.disposableFetchBackoff,
+		onUpdate: v.onDisposableUpdate,
+		logger:   v.logger,
+	}
+}
+
+// run updates the domains, retrying the failed fetch, and notifies the outcome
+func (u *disposableUpdater) run() {
+	var ret DisposableUpdate
+	backoff := u.backoff
+	for ret.Attempts = 1; ; ret.Attempts++ {
+		ret.Domains, ret.Err = u.update()
+		if ret.Err == nil || ret.Err == ErrDisposableCheckDisabled || errors.Is(ret.Err, ErrDisposableTooLarge) || ret.Attempts >= u.attempts {
+			break
+		}
+		if !u.sleep(backoff) {
+			break
+		}
+		backoff *= 2
+	}
+	ret.At = time.Now()
+
+	if u.logger != nil {
+		u.logger.Debug("disposable update", "source", u.source, "domains", ret.Domains, "attempts", ret.Attempts, "error", ret.Err)
+	}
+	if u.onUpdate != nil {
+		u.onUpdate(ret)
+	}
+}
+
+// context returns the context cancelling the run
+func (u *disposableUpdater) context() context.Context {
+	if u.ctx == nil {
+		return context.Background()
+	}
+	return u.ctx
+}
+
+// sleep waits for the backoff before the retry, returns false when the run is cancelled meanwhile
+func (u *disposableUpdater) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-u.context().Done():
+		return false
+	}
+}
+
+// update gets domains data from source's URL and returns the number of the domains. They replace the domains of the repo when replace
+// is set and the repo implements DisposableRepoReplacer, otherwise they are added and the domains dropped by the source
+// since the last update are removed when the repo implements DisposableRepoMaintainer and fetched is set.
+func (u *disposableUpdater) update() (int, error) {
+	if u.repo == nil {
+		return 0, ErrDisposableCheckDisabled
+	}
+
+	client, timeout, maxSize := u.client, u.timeout, u.maxSize
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if timeout <= 0 {
+		timeout = defaultDisposableFetchTimeout
+	}
+	if maxSize <= 0 {
+		maxSize = defaultDisposableMaxSize
+	}
+
+	ctx, cancel := context.WithTimeout(u.context(), timeout)
 	defer cancel()
-	req, err := http.NewRequest("GET", source, nil)
+	req, err := http.NewRequest("GET", u.source, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("get disposable domains from %s with status_code: %d", source, resp.StatusCode)
+		return 0, fmt.Errorf("get disposable domains from %s with status_code: %d", u.source, resp.StatusCode)
 	}
 
-	var domains []string
+	body, err := decompressedBody(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("get disposable domains from %s: %w", u.source, err)
+	}
+	defer body.Close()
+	// One byte over the limit tells the body exceeding it, the limit applies to the decompressed body
+	content, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return 0, err
+	}
+	if int64(len(content)) > maxSize {
+		return 0, fmt.Errorf("get disposable domains from %s: %w", u.source, ErrDisposableTooLarge)
+	}
 
-	content, err := io.ReadAll(resp.Body)
+	domains, err := parseDisposableDomains(content)
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("parse disposable domains from %s: %w", u.source, err)
+	}
+	if len(domains) == 0 {
+		return 0, nil
 	}
 
-	if len(content) == 0 {
-		return nil
+	if r, ok := u.repo.(DisposableRepoReplacer); ok && u.replace {
+		r.ReplaceDisposableDomains(domains)
+		return len(domains), nil
 	}
 
-	if err = json.Unmarshal(content, &domains); err != nil {
+	u.repo.AddDisposableDomains(domains)
+	if u.fetched != nil {
+		dropped := u.fetched.replace(domains)
+		if m, ok := u.repo.(DisposableRepoMaintainer); ok && len(dropped) > 0 {
+			m.RemoveDisposableDomains(dropped)
+		}
+	}
+
+	return len(domains), nil
+}
+
+// decompressedBody returns the reader of the body decompressed when it is gzip-compressed, i.e. served with
+// Content-Encoding: gzip which the HTTP client did not decompress itself, or a .gz file. The compression is
+// told by the gzip magic bytes, so a .gz file served decompressed is read as is. Closing the reader closes
+// the body as well.
+func decompressedBody(body io.ReadCloser) (io.ReadCloser, error) {
+	r := bufio.NewReader(body)
+	magic, err := r.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		// The body shorter than the magic bytes is parsed as is
+		return readCloser{r, body.Close}, nil
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser{zr, func() error {
+		err := zr.Close()
+		if cerr := body.Close(); err == nil {
+			err = cerr
+		}
 		return err
+	}}, nil
+}
+
+// readCloser reads from the reader and closes by the func
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error { return r.close() }
+
+// parseDisposableDomains parses a list of domains, either as a JSON array, as a JSON object
+// keyed by domain or as newline-delimited text (blank lines and `#` comments are skipped)
+func parseDisposableDomains(content []byte) ([]string, error) {
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 {
+		return nil, nil
+	}
+
+	switch content[0] {
+	case '[':
+		var domains []string
+		if err := json.Unmarshal(content, &domains); err != nil {
+			return nil, fmt.Errorf("malformed JSON array of disposable domains: %w", err)
+		}
+		return domains, nil
+	case '{':
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(content, &object); err != nil {
+			return nil, fmt.Errorf("malformed JSON object of disposable domains: %w", err)
+		}
+		domains := make([]string, 0, len(object))
+		for domain := range object {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+		return domains, nil
 	}
 
-	updater.AddDisposableDomains(domains)
+	var domains []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.ContainsAny(line, " \t,;\"'<>{}[]") || !strings.Contains(line, ".") {
+			if len(domains) == 0 {
+				return nil, fmt.Errorf("unknown format of disposable domains starting with %q", head(content, 32))
+			}
+			return nil, fmt.Errorf("malformed disposable domain at line %d: %q", n, line)
+		}
+		domains = append(domains, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
 
-	return nil
+// head returns at most the first n bytes of the content
+func head(content []byte, n int) []byte {
+	if len(content) > n {
+		return content[:n]
+	}
+	return content
 }
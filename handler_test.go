@@ -1,8 +1,19 @@
 package emailverifier
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/h2non/gock.v1"
@@ -19,7 +30,7 @@ func TestUpdateDisposableDomainsOK(t *testing.T) {
 		Reply(http.StatusOK).
 		JSON(mockResp)
 
-	err := updateDisposableDomains(disposableDataURL, verifier.disposableRepo)
+	_, err := (&disposableUpdater{source: disposableDataURL, repo: verifier.disposableRepo}).update()
 	assert.NoError(t, err)
 	assert.True(t, verifier.IsDisposable("a.org"))
 	assert.True(t, verifier.IsDisposable("b.com"))
@@ -29,7 +40,7 @@ func TestUpdateDisposableDomainsOK(t *testing.T) {
 
 func TestUpdateDisposableDomainsFailed_NoSuchHost(t *testing.T) {
 
-	err := updateDisposableDomains("http://abcmockxyz.aaa", newDisposableRepo())
+	_, err := (&disposableUpdater{source: "http://abcmockxyz.aaa", repo: newDisposableRepo()}).update()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no such host")
 }
@@ -40,7 +51,7 @@ func TestUpdateDisposableDomainsFailed_StatusNotFound(t *testing.T) {
 		Get("/disposable/disposable-email-domains/master/domains.json").
 		Reply(http.StatusNotFound)
 
-	err := updateDisposableDomains(disposableDataURL, newDisposableRepo())
+	_, err := (&disposableUpdater{source: disposableDataURL, repo: newDisposableRepo()}).update()
 	assert.Error(t, err, "get disposable domains from https://raw.githubusercontent.com/disposable/disposable-email-domains/master/domains.json with status_code: 404")
 }
 
@@ -50,7 +61,7 @@ func TestUpdateDisposableDomainsFailed_StatusInternalError(t *testing.T) {
 		Get("/disposable/disposable-email-domains/master/domains.json").
 		Reply(http.StatusInternalServerError)
 
-	err := updateDisposableDomains(disposableDataURL, newDisposableRepo())
+	_, err := (&disposableUpdater{source: disposableDataURL, repo: newDisposableRepo()}).update()
 	assert.Error(t, err, "get disposable domains from https://raw.githubusercontent.com/disposable/disposable-email-domains/master/domains.json with status_code: 500")
 }
 
@@ -61,7 +72,7 @@ func TestUpdateDisposableDomains_NoResponse(t *testing.T) {
 		Get("/disposable/disposable-email-domains/master/domains.json").
 		Reply(http.StatusOK)
 
-	err := updateDisposableDomains(disposableDataURL, newDisposableRepo())
+	_, err := (&disposableUpdater{source: disposableDataURL, repo: newDisposableRepo()}).update()
 	assert.NoError(t, err)
 }
 
@@ -73,6 +84,353 @@ func TestUpdateDisposableDomains_WrongResponse(t *testing.T) {
 		Reply(http.StatusOK).
 		JSON("testing")
 
-	err := updateDisposableDomains(disposableDataURL, newDisposableRepo())
-	assert.Error(t, err, "invalid character 'e' in literal true (expecting 'r')")
+	_, err := (&disposableUpdater{source: disposableDataURL, repo: newDisposableRepo()}).update()
+	assert.EqualError(t, err, `parse disposable domains from `+disposableDataURL+`: unknown format of disposable domains starting with "testing"`)
+}
+
+func TestUpdateDisposableDomainsOK_NewlineDelimited(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://raw.githubusercontent.com").
+		Get("/disposable/disposable-email-domains/master/domains.txt").
+		Reply(http.StatusOK).
+		BodyString("a.org\nb.com\n")
+
+	repo := newDisposableRepo()
+	_, err := (&disposableUpdater{source: "https://raw.githubusercontent.com/disposable/disposable-email-domains/master/domains.txt", repo: repo}).update()
+	assert.NoError(t, err)
+	assert.True(t, repo.IsDomainDisposable("a.org"))
+	assert.True(t, repo.IsDomainDisposable("b.com"))
+}
+
+func TestUpdateDisposableDomainsOK_RemovesDropped(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://raw.githubusercontent.com").
+		Get("/disposable/disposable-email-domains/master/domains.json").
+		Reply(http.StatusOK).
+		JSON([]string{"a.org", "b.com"})
+	gock.New("https://raw.githubusercontent.com").
+		Get("/disposable/disposable-email-domains/master/domains.json").
+		Reply(http.StatusOK).
+		JSON([]string{"b.com", "c.net"})
+
+	repo := newDisposableRepo()
+	repo.AddDisposableDomains([]string{"manual.org"})
+	updater := &disposableUpdater{source: disposableDataURL, repo: repo, fetched: &fetchedDomains{}}
+	n, err := updater.update()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 3, repo.Count())
+
+	_, err = updater.update()
+	assert.NoError(t, err)
+	assert.False(t, repo.IsDomainDisposable("a.org"))
+	assert.True(t, repo.IsDomainDisposable("b.com"))
+	assert.True(t, repo.IsDomainDisposable("c.net"))
+	assert.True(t, repo.IsDomainDisposable("manual.org"))
+	assert.Equal(t, 3, repo.Count())
+}
+
+func TestUpdateDisposableDomainsOK_Replace(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://raw.githubusercontent.com").
+		Get("/disposable/disposable-email-domains/master/domains.json").
+		Reply(http.StatusOK).
+		JSON([]string{"b.com", "c.net"})
+
+	repo := newDisposableRepo()
+	repo.AddDisposableDomains([]string{"a.org", "manual.org"})
+	_, err := (&disposableUpdater{source: disposableDataURL, repo: repo, replace: true}).update()
+	assert.NoError(t, err)
+	assert.False(t, repo.IsDomainDisposable("a.org"))
+	assert.False(t, repo.IsDomainDisposable("manual.org"))
+	assert.True(t, repo.IsDomainDisposable("b.com"))
+	assert.Equal(t, 2, repo.Count())
+}
+
+func TestDisposableUpdaterRun_Retry(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`["a.org", "b.com"]`))
+	}))
+	defer srv.Close()
+
+	var updates []DisposableUpdate
+	repo := newDisposableRepo()
+	v := NewVerifier().
+		EnableDisposableCheck(repo).
+		DisposableFetchRetry(3, time.Millisecond).
+		OnDisposableUpdate(func(u DisposableUpdate) { updates = append(updates, u) })
+	v.newDisposableUpdater(srv.URL).run()
+
+	assert.Len(t, updates, 1)
+	assert.Equal(t, 2, updates[0].Domains)
+	assert.Equal(t, 3, updates[0].Attempts)
+	assert.NoError(t, updates[0].Err)
+	assert.False(t, updates[0].At.IsZero())
+	assert.True(t, repo.IsDomainDisposable("a.org"))
+}
+
+func TestDisposableUpdaterRun_Exhausted(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	var updates []DisposableUpdate
+	v := NewVerifier().
+		EnableDisposableCheck(newDisposableRepo()).
+		DisposableFetchRetry(2, time.Millisecond).
+		OnDisposableUpdate(func(u DisposableUpdate) { updates = append(updates, u) })
+	v.newDisposableUpdater(srv.URL).run()
+
+	assert.Len(t, updates, 1)
+	assert.Equal(t, 2, updates[0].Attempts)
+	assert.Error(t, updates[0].Err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestDisposableUpdaterRun_Cancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	var updates []DisposableUpdate
+	v := NewVerifier().
+		EnableDisposableCheck(newDisposableRepo()).
+		DisposableFetchRetry(3, time.Hour).
+		OnDisposableUpdate(func(u DisposableUpdate) { updates = append(updates, u) })
+	updater := v.newDisposableUpdater(srv.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	updater.ctx = ctx
+	updater.run()
+
+	assert.Len(t, updates, 1)
+	assert.Equal(t, 1, updates[0].Attempts)
+	assert.Error(t, updates[0].Err)
+}
+
+func TestUpdateDisposableDomainsOK_Gzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`["a.org", "b.com"]`))
+	assert.NoError(t, zw.Close())
+
+	for _, encoded := range []bool{true, false} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if encoded {
+				w.Header().Set("Content-Encoding", "gzip")
+			} else {
+				// A .gz file
+				w.Header().Set("Content-Type", "application/gzip")
+			}
+			_, _ = w.Write(buf.Bytes())
+		}))
+
+		repo := newDisposableRepo()
+		n, err := NewVerifier().EnableDisposableCheck(repo).newDisposableUpdater(srv.URL + "/domains.json.gz").update()
+		srv.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.True(t, repo.IsDomainDisposable("a.org"))
+		assert.True(t, repo.IsDomainDisposable("b.com"))
+	}
+}
+
+// closeRecorder records whether the body was closed
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestDecompressedBodyOK_Close(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`["a.org"]`))
+	_ = zw.Close()
+
+	for _, data := range [][]byte{buf.Bytes(), []byte(`["a.org"]`)} {
+		body := &closeRecorder{Reader: bytes.NewReader(data)}
+		r, err := decompressedBody(body)
+		assert.NoError(t, err)
+		content, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, `["a.org"]`, string(content))
+		assert.NoError(t, r.Close())
+		assert.True(t, body.closed)
+	}
+}
+
+func TestUpdateDisposableDomainsFailed_GzipTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`["` + strings.Repeat("a", 1024) + `.org"]`))
+	assert.NoError(t, zw.Close())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	// The compressed body fits the limit, but the decompressed one does not
+	v := NewVerifier().EnableDisposableCheck(newDisposableRepo()).DisposableMaxSize(int64(buf.Len() + 1))
+	_, err := v.newDisposableUpdater(srv.URL).update()
+	assert.ErrorIs(t, err, ErrDisposableTooLarge)
+}
+
+func TestDisposableUpdaterRun_TooLarge(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`["a.org", "b.com", "c.net"]`))
+	}))
+	defer srv.Close()
+
+	var updates []DisposableUpdate
+	repo := newDisposableRepo()
+	v := NewVerifier().
+		EnableDisposableCheck(repo).
+		DisposableMaxSize(16).
+		DisposableFetchRetry(3, time.Millisecond).
+		OnDisposableUpdate(func(u DisposableUpdate) { updates = append(updates, u) })
+	v.newDisposableUpdater(srv.URL).run()
+
+	assert.Len(t, updates, 1)
+	assert.ErrorIs(t, updates[0].Err, ErrDisposableTooLarge)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.False(t, repo.IsDomainDisposable("a.org"))
+
+	// The response of the limit size is accepted
+	v.DisposableMaxSize(int64(len(`["a.org", "b.com", "c.net"]`)))
+	_, err := v.newDisposableUpdater(srv.URL).update()
+	assert.NoError(t, err)
+	assert.True(t, repo.IsDomainDisposable("a.org"))
+}
+
+func TestDisposableUpdaterRun_Callback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["a.org", "b.com", "c.net"]`))
+	}))
+	defer srv.Close()
+
+	var (
+		count  int
+		gotErr error
+		at     time.Time
+	)
+	start := time.Now()
+	v := NewVerifier().
+		EnableDisposableCheck(newDisposableRepo()).
+		SetDisposableUpdateCallback(func(c int, err error, t time.Time) { count, gotErr, at = c, err, t })
+	v.newDisposableUpdater(srv.URL).run()
+
+	assert.Equal(t, 3, count)
+	assert.NoError(t, gotErr)
+	assert.False(t, at.Before(start))
+}
+
+// roundTripperFunc serves the HTTP requests by the function
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestUpdateDisposableDomainsFailed_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`["a.org"]`))
+	}))
+	defer srv.Close()
+
+	v := NewVerifier().EnableDisposableCheck(newDisposableRepo()).DisposableFetchTimeout(50 * time.Millisecond)
+	_, err := v.newDisposableUpdater(srv.URL).update()
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestUpdateDisposableDomainsOK_HTTPClient(t *testing.T) {
+	var requested string
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requested = r.URL.String()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`["a.org"]`)),
+			Request:    r,
+		}, nil
+	})}
+
+	repo := newDisposableRepo()
+	v := NewVerifier().EnableDisposableCheck(repo).DisposableHTTPClient(client)
+	_, err := v.newDisposableUpdater(disposableDataURL).update()
+	assert.NoError(t, err)
+	assert.Equal(t, disposableDataURL, requested)
+	assert.True(t, repo.IsDomainDisposable("a.org"))
+}
+
+func TestParseDisposableDomainsOK_JSONArray(t *testing.T) {
+	domains, err := parseDisposableDomains([]byte(` ["a.org", "b.com"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.org", "b.com"}, domains)
+}
+
+func TestParseDisposableDomainsOK_NewlineDelimited(t *testing.T) {
+	domains, err := parseDisposableDomains([]byte("# disposable domains\na.org\r\n\n  b.com  \n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.org", "b.com"}, domains)
+}
+
+func TestParseDisposableDomainsOK_JSONObject(t *testing.T) {
+	domains, err := parseDisposableDomains([]byte(`{"b.com": true, "a.org": 1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.org", "b.com"}, domains)
+}
+
+func TestParseDisposableDomainsFailed_UnknownFormat(t *testing.T) {
+	_, err := parseDisposableDomains([]byte("<!DOCTYPE html><html><head><title>Not Found</title></head></html>"))
+	assert.EqualError(t, err, `unknown format of disposable domains starting with "<!DOCTYPE html><html><head><titl"`)
+}
+
+func TestParseDisposableDomainsFailed_Malformed(t *testing.T) {
+	_, err := parseDisposableDomains([]byte(`["a.org",`))
+	assert.Error(t, err)
+
+	_, err = parseDisposableDomains([]byte(`{"a.org": true`))
+	assert.Error(t, err)
+
+	_, err = parseDisposableDomains([]byte("a.org\nb.com c.net\n"))
+	assert.EqualError(t, err, `malformed disposable domain at line 2: "b.com c.net"`)
+}
+
+func TestLoadDisposableFromFileOK(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("a.org\nb.com\n"), 0o600))
+
+	verifier := NewVerifier().EnableDisposableCheck(newDisposableRepo())
+	assert.NoError(t, verifier.LoadDisposableFromFile(path))
+	assert.True(t, verifier.IsDisposable("a.org"))
+	assert.True(t, verifier.IsDisposable("b.com"))
+	assert.False(t, verifier.IsDisposable("c.net"))
+}
+
+func TestLoadDisposableFromFileFailed(t *testing.T) {
+	verifier := NewVerifier().EnableDisposableCheck(newDisposableRepo())
+	assert.Error(t, verifier.LoadDisposableFromFile(filepath.Join(t.TempDir(), "missing.json")))
+	assert.Error(t, NewVerifier().LoadDisposableFromFile("domains.json"))
+}
+
+func TestUpdateDisposableDomainsFailed_NoRepo(t *testing.T) {
+	_, err := (&disposableUpdater{source: disposableDataURL}).update()
+	assert.Equal(t, ErrDisposableCheckDisabled, err)
 }
@@ -0,0 +1,86 @@
+package emailverifier
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// confusables maps characters to the ASCII characters they are commonly mistaken for
+var confusables = map[rune]rune{
+	// Latin and digits
+	'I': 'l', '1': 'l', '0': 'o', 'ı': 'i', 'ℓ': 'l', 'ɡ': 'g',
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j', 'к': 'k', 'м': 'm',
+	'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's',
+	'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ӏ': 'l',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x',
+}
+
+// multiCharConfusables replaces character sequences commonly mistaken for a single character
+var multiCharConfusables = strings.NewReplacer("rn", "m", "vv", "w")
+
+var (
+	skeletonIndexOnce sync.Once
+	skeletonIndex     map[string]string // skeleton -> popular domain
+)
+
+// LookalikeDomain checks if the domain is a homoglyph of a popular domain, i.e. it looks the same
+// after the confusable characters (e.g. Cyrillic "а" or capital "I") are replaced by the ASCII ones
+// they imitate, and returns the imitated domain or an empty string otherwise.
+// The domain is expected as submitted, since lowercasing hides some of the confusable characters.
+func (v *Verifier) LookalikeDomain(domain string) string {
+	normalized := strings.ToLower(DomainToASCII(domain))
+	if domain == "" || freeDomains[normalized] || v.suggestionDomains.has(normalized) {
+		return ""
+	}
+
+	skeleton := domainSkeleton(domain)
+	skeletonIndexOnce.Do(buildSkeletonIndex)
+	if d, ok := skeletonIndex[skeleton]; ok {
+		return d
+	}
+	for d := range v.suggestionDomains.snapshot() {
+		if domainSkeleton(d) == skeleton {
+			return d
+		}
+	}
+	return ""
+}
+
+// buildSkeletonIndex indexes the popular domains by their skeleton, when more domains
+// share the skeleton, the lexicographically smallest one wins to keep the result stable
+func buildSkeletonIndex() {
+	domains := make([]string, 0, len(freeDomains))
+	for d := range freeDomains {
+		domains = append(domains, d)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(domains)))
+
+	skeletonIndex = make(map[string]string, len(domains))
+	for _, d := range domains {
+		skeletonIndex[domainSkeleton(d)] = d
+	}
+}
+
+// domainSkeleton returns the skeleton of the domain, the form in which lookalike domains are equal
+func domainSkeleton(domain string) string {
+	if u, err := idna.ToUnicode(domain); err == nil {
+		domain = u
+	}
+
+	var b strings.Builder
+	for _, r := range domain {
+		if c, ok := confusables[r]; ok {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return multiCharConfusables.Replace(b.String())
+}
@@ -0,0 +1,47 @@
+package emailverifier
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookalikeDomain_Cyrillic(t *testing.T) {
+	assert.Equal(t, "gmail.com", verifier.LookalikeDomain("gmаil.com"))
+	assert.Equal(t, "yahoo.com", verifier.LookalikeDomain("yаhоо.com"))
+}
+
+func TestLookalikeDomain_Punycode(t *testing.T) {
+	assert.Equal(t, "gmail.com", verifier.LookalikeDomain(DomainToASCII("gmаil.com")))
+}
+
+func TestLookalikeDomain_CapitalI(t *testing.T) {
+	assert.Equal(t, "gmail.com", verifier.LookalikeDomain("gmaiI.com"))
+}
+
+func TestLookalikeDomain_MultiChar(t *testing.T) {
+	assert.Equal(t, "hotmail.com", verifier.LookalikeDomain("hotrnail.com"))
+}
+
+func TestLookalikeDomain_NotLookalike(t *testing.T) {
+	assert.Empty(t, verifier.LookalikeDomain("gmail.com"))
+	assert.Empty(t, verifier.LookalikeDomain("GMAIL.com"))
+	assert.Empty(t, verifier.LookalikeDomain("github.com"))
+	assert.Empty(t, verifier.LookalikeDomain(""))
+}
+
+func TestSuggestDomainOK_Homoglyph(t *testing.T) {
+	assert.Equal(t, "gmail.com", verifier.SuggestDomain("gmаil.com"))
+}
+
+func TestCheckEmail_DomainLookalike(t *testing.T) {
+	v := NewVerifier().EnableDisposableCheck(newDisposableRepo()).EnableMXResolver(&mockResolver{
+		mx: map[string][]*net.MX{"gmaii.com": {{Host: "mx.gmaii.com.", Pref: 10}}},
+	}).EnableDomainSuggest()
+
+	ret, err := v.Verify("someone@gmaiI.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.DomainLookalike)
+	assert.Equal(t, "gmail.com", ret.Suggestion)
+}
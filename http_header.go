@@ -0,0 +1,102 @@
+package emailverifier
+
+import "net/http"
+
+// UserAgent sets the User-Agent header of all the outbound HTTP requests, i.e. of the disposable fetch,
+// the gravatar check, the RDAP lookup and the API verifiers, replacing their own. An empty ua restores them.
+func (v *Verifier) UserAgent(ua string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.httpUserAgent = ua
+	return v
+}
+
+// HTTPHeaders sets the headers of all the outbound HTTP requests, e.g. the authorization required by a private
+// source of the disposable domains, replacing the headers of the same name set by the requests themselves.
+// It takes effect for the automatic update of the disposable domains enabled afterwards. nil removes them.
+func (v *Verifier) HTTPHeaders(h http.Header) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// The headers are copied as the snapshots taken by the running checks share them
+	v.httpHeader = h.Clone()
+	return v
+}
+
+// hasHTTPHeaders reports whether any header of the outbound HTTP requests is configured
+func (v *Verifier) hasHTTPHeaders() bool {
+	return len(v.httpHeader) > 0 || v.httpUserAgent != ""
+}
+
+// httpClient returns the client sending the configured headers on behalf of c
+func (v *Verifier) httpClient(c *http.Client) *http.Client {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	if !v.hasHTTPHeaders() {
+		return c
+	}
+
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ret := *c
+	ret.Transport = &headerTransport{base: base, header: v.httpHeader, userAgent: v.httpUserAgent}
+	return &ret
+}
+
+// headerTransport sets the headers of the requests sent through the base transport
+type headerTransport struct {
+	base      http.RoundTripper
+	header    http.Header
+	userAgent string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	for k, vs := range t.header {
+		req.Header[k] = vs
+	}
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// clientWrapper is implemented by the API verifiers whose HTTP client can be replaced,
+// so they send the configured headers as well
+type clientWrapper interface {
+	wrapClient(wrap func(*http.Client) *http.Client) smtpAPIVerifier
+}
+
+// wrappedClientProvider wraps the clients made by the provider
+type wrappedClientProvider struct {
+	cp   ClientProvider
+	wrap func(*http.Client) *http.Client
+}
+
+func (p wrappedClientProvider) MakeClient(host string) (*http.Client, error) {
+	c, err := p.cp.MakeClient(host)
+	if err != nil {
+		return nil, err
+	}
+	return p.wrap(c), nil
+}
+
+func (g gmail) wrapClient(wrap func(*http.Client) *http.Client) smtpAPIVerifier {
+	g.client = wrap(g.client)
+	return g
+}
+
+func (o outlook) wrapClient(wrap func(*http.Client) *http.Client) smtpAPIVerifier {
+	o.client = wrap(o.client)
+	return o
+}
+
+func (y yahoo) wrapClient(wrap func(*http.Client) *http.Client) smtpAPIVerifier {
+	y.cp = wrappedClientProvider{cp: y.cp, wrap: wrap}
+	return y
+}
@@ -0,0 +1,88 @@
+package emailverifier
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPClientOK_Headers(t *testing.T) {
+	var got http.Header
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.Header
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+	})}
+
+	v := NewVerifier().UserAgent("verifier/1.0").HTTPHeaders(http.Header{"Authorization": {"Bearer token"}})
+	req, err := http.NewRequest("GET", "https://example.test/", nil)
+	assert.NoError(t, err)
+	req.Header.Set("User-Agent", "own")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.httpClient(client).Do(req)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "verifier/1.0", got.Get("User-Agent"))
+	assert.Equal(t, "Bearer token", got.Get("Authorization"))
+	assert.Equal(t, "application/json", got.Get("Accept"))
+	// The request itself is left intact
+	assert.Equal(t, "own", req.Header.Get("User-Agent"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestHTTPClientOK_NoHeaders(t *testing.T) {
+	client := &http.Client{}
+	assert.Same(t, client, NewVerifier().httpClient(client))
+	assert.Same(t, http.DefaultClient, NewVerifier().httpClient(nil))
+}
+
+func TestUpdateDisposableDomainsOK_HTTPHeaders(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`["a.org"]`))
+	}))
+	defer srv.Close()
+
+	repo := newDisposableRepo()
+	v := NewVerifier().EnableDisposableCheck(repo).HTTPHeaders(http.Header{"Authorization": {"Bearer token"}})
+	_, err := v.newDisposableUpdater(srv.URL).update()
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token", auth)
+	assert.True(t, repo.IsDomainDisposable("a.org"))
+}
+
+func TestCheckGravatarOK_UserAgent(t *testing.T) {
+	var agents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	v := NewVerifier().EnableGravatarProfile().GravatarBaseURL(srv.URL + "/").UserAgent("verifier/1.0")
+	_, err := v.CheckGravatar("someone@example.com")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, agents)
+	for _, agent := range agents {
+		assert.Equal(t, "verifier/1.0", agent)
+	}
+}
+
+func TestAPIVerifierOK_WrapClient(t *testing.T) {
+	v := NewVerifier().UserAgent("verifier/1.0")
+
+	wrapped := newYahooAPIVerifier(nil).(clientWrapper).wrapClient(v.httpClient)
+	client, err := wrapped.(yahoo).cp.MakeClient("yahoo.com")
+	assert.NoError(t, err)
+	assert.IsType(t, &headerTransport{}, client.Transport)
+
+	wrapped = newGmailAPIVerifier(nil).(clientWrapper).wrapClient(v.httpClient)
+	assert.IsType(t, &headerTransport{}, wrapped.(gmail).client.Transport)
+	// The client of the verifier enabled is not modified
+	assert.Nil(t, http.DefaultClient.Transport)
+}
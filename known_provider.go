@@ -0,0 +1,71 @@
+package emailverifier
+
+import "strings"
+
+// KnownProvider describes the behavior of a big email provider, see EnableKnownProviders
+type KnownProvider struct {
+	Name     string         // e.g. "gmail"
+	API      string         // the API verifier checking its addresses, e.g. GMAIL, empty when there is none
+	CatchAll CatchAllStatus // CatchAllNo when it rejects the unknown users, CatchAllYes when it accepts any, empty when not known
+}
+
+// EnableKnownProviders short-circuits the SMTP check of the addresses at the known providers (e.g. gmail.com):
+// they are checked by the API verifier of the provider when it is enabled, see EnableAPIVerifier, without
+// the MX lookup and the connection to port 25. Otherwise their catch-all status is taken from the table
+// of the known providers instead of probing a random address.
+func (v *Verifier) EnableKnownProviders() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.knownProvidersEnabled = true
+	return v
+}
+
+// DisableKnownProviders checks the addresses at the known providers like any other
+func (v *Verifier) DisableKnownProviders() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.knownProvidersEnabled = false
+	return v
+}
+
+// AddKnownProviders adds the providers keyed by domain to the built-in ones, replacing them for the same domain
+func (v *Verifier) AddKnownProviders(providers map[string]KnownProvider) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// The map is replaced rather than modified as the snapshots taken by the running checks share it
+	merged := make(map[string]KnownProvider, len(v.knownProviders)+len(providers))
+	for domain, p := range v.knownProviders {
+		merged[domain] = p
+	}
+	for domain, p := range providers {
+		merged[strings.ToLower(domain)] = p
+	}
+	v.knownProviders = merged
+	return v
+}
+
+// knownProvider returns the known provider of the domain, if the known providers are enabled
+func (v *Verifier) knownProvider(domain string) (KnownProvider, bool) {
+	if !v.knownProvidersEnabled {
+		return KnownProvider{}, false
+	}
+	domain = strings.ToLower(domain)
+	if p, ok := v.knownProviders[domain]; ok {
+		return p, true
+	}
+	p, ok := knownProviders[domain]
+	return p, ok
+}
+
+// knownProviderAPI returns the enabled API verifier of the known provider of the domain and its name
+func (v *Verifier) knownProviderAPI(domain string) (smtpAPIVerifier, string, bool) {
+	p, ok := v.knownProvider(domain)
+	if !ok || p.API == "" {
+		return nil, "", false
+	}
+	apiVerifier, ok := v.apiVerifiers[p.API]
+	return apiVerifier, p.API, ok
+}
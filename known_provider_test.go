@@ -0,0 +1,79 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKnownProviderOK_BuiltIn(t *testing.T) {
+	verifier := NewVerifier().EnableKnownProviders()
+
+	p, ok := verifier.knownProvider("GMail.com")
+	assert.True(t, ok)
+	assert.Equal(t, KnownProvider{Name: "gmail", API: GMAIL, CatchAll: CatchAllNo}, p)
+
+	_, ok = verifier.knownProvider("example.test")
+	assert.False(t, ok)
+
+	_, ok = verifier.DisableKnownProviders().knownProvider("gmail.com")
+	assert.False(t, ok)
+}
+
+func TestCheckSMTPOK_KnownProviderByAPI(t *testing.T) {
+	// Without the MX records the check fails unless it is done by the API
+	verifier := NewVerifier().EnableSMTPCheck().EnableMXResolver(&mockResolver{}).EnableKnownProviders()
+	verifier.apiVerifiers[GMAIL] = fakeAPIVerifier{}
+
+	smtp, err := verifier.CheckSMTP("gmail.com", "someone")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, Deliverable: true, UsingAPI: true}, smtp)
+}
+
+func TestCheckSMTPFailed_KnownProviderAPIDisabled(t *testing.T) {
+	verifier := NewVerifier().EnableSMTPCheck().EnableMXResolver(&mockResolver{}).EnableKnownProviders()
+
+	_, err := verifier.CheckSMTP("gmail.com", "someone")
+	assert.Error(t, err)
+}
+
+func TestCheckSMTPOK_KnownProviderCatchAll(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier(domain).EnableKnownProviders().
+		AddKnownProviders(map[string]KnownProvider{domain: {Name: "example", CatchAll: CatchAllYes}})
+
+	smtp, err := verifier.CheckSMTP(domain, "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.CatchAll)
+	assert.Equal(t, CatchAllYes, smtp.CatchAllStatus)
+	assert.Empty(t, filterCommands(srv.received(), "RCPT"))
+}
+
+func TestCheckSMTPOK_KnownProviderNoCatchAll(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier(domain).EnableKnownProviders().
+		AddKnownProviders(map[string]KnownProvider{domain: {Name: "example", CatchAll: CatchAllNo}})
+
+	smtp, err := verifier.CheckSMTP(domain, "someone")
+	assert.NoError(t, err)
+	assert.False(t, smtp.CatchAll)
+	assert.Equal(t, CatchAllNo, smtp.CatchAllStatus)
+	assert.True(t, smtp.Deliverable)
+	// Only the address itself is probed
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 1)
+}
+
+func TestCheckSMTPOK_KnownProvidersDisabled(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier(domain).
+		AddKnownProviders(map[string]KnownProvider{domain: {Name: "example", CatchAll: CatchAllNo}})
+
+	smtp, err := verifier.CheckSMTP(domain, "someone")
+	assert.NoError(t, err)
+	// The mock server accepts any address
+	assert.True(t, smtp.CatchAll)
+	assert.Equal(t, CatchAllYes, smtp.CatchAllStatus)
+}
@@ -0,0 +1,20 @@
+package emailverifier
+
+import "log/slog"
+
+// SetLogger sets the logger receiving the debug-level events of the verifier: the MX lookups, SMTP dials,
+// RCPT replies, disposable domains updates and API verifier calls. nil disables the logging (default).
+func (v *Verifier) SetLogger(l *slog.Logger) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.logger = l
+	return v
+}
+
+// debug logs the event at the debug level when a logger is set
+func (v *Verifier) debug(msg string, args ...interface{}) {
+	if v.logger != nil {
+		v.logger.DebugContext(v.context(), msg, args...)
+	}
+}
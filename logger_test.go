@@ -0,0 +1,79 @@
+package emailverifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loggedEvents decodes the events logged by the JSON handler
+func loggedEvents(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var events []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &event), line)
+		events = append(events, event)
+	}
+	return events
+}
+
+func newDebugLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestSetLoggerOK_Verify(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@" + domain)
+
+	var buf bytes.Buffer
+	_, err := srv.verifier(domain).SetLogger(newDebugLogger(&buf)).Verify("someone@" + domain)
+	assert.NoError(t, err)
+
+	var msgs []string
+	var rcpts []map[string]interface{}
+	for _, event := range loggedEvents(t, &buf) {
+		assert.Equal(t, "DEBUG", event["level"])
+		msgs = append(msgs, event["msg"].(string))
+		if event["msg"] == "smtp rcpt" {
+			rcpts = append(rcpts, event)
+		}
+	}
+	// Both the MX and the SMTP check resolve the MX records without the MX cache
+	assert.Equal(t, []string{"mx lookup", "mx lookup", "smtp dial", "smtp rcpt", "smtp rcpt"}, msgs)
+	// The catch-all probe is rejected, the address is accepted
+	assert.Equal(t, float64(550), rcpts[0]["status"])
+	assert.Equal(t, float64(250), rcpts[1]["status"])
+	assert.Equal(t, "mx."+domain+".", rcpts[1]["host"])
+	assert.Equal(t, domain, rcpts[1]["domain"])
+}
+
+func TestSetLoggerOK_DisposableUpdate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["a.org", "b.com"]`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	v := NewVerifier().EnableDisposableCheck(newDisposableRepo()).SetLogger(newDebugLogger(&buf))
+	v.newDisposableUpdater(srv.URL).run()
+
+	events := loggedEvents(t, &buf)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "disposable update", events[0]["msg"])
+	assert.Equal(t, srv.URL, events[0]["source"])
+	assert.Equal(t, float64(2), events[0]["domains"])
+}
+
+func TestSetLoggerOK_Disabled(t *testing.T) {
+	var buf bytes.Buffer
+	v := NewVerifier().SetLogger(newDebugLogger(&buf)).SetLogger(nil)
+	v.debug("event")
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,108 @@
+package emailverifier
+
+import (
+	"net"
+	"sync"
+)
+
+// MailServices are the mail access services running at the MX hosts or the domain host,
+// a weak signal that the domain is a real mail host when the SMTP check is not possible
+type MailServices struct {
+	Submission bool `json:"submission"` // the message submission port 587 is open
+	IMAP       bool `json:"imap"`       // the IMAP over TLS port 993 is open
+	POP3       bool `json:"pop3"`       // the POP3 over TLS port 995 is open
+}
+
+// open reports whether any of the services is running
+func (s *MailServices) open() bool {
+	return s != nil && (s.Submission || s.IMAP || s.POP3)
+}
+
+// EnableMailServicesCheck probes the mail access services of the domain in Verify, see CheckMailServices
+func (v *Verifier) EnableMailServicesCheck() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.mailServicesCheck = true
+	return v
+}
+
+// DisableMailServicesCheck disables the probe of the mail access services (default)
+func (v *Verifier) DisableMailServicesCheck() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.mailServicesCheck = false
+	return v
+}
+
+// CheckMailServices reports which of the submission, IMAP and POP3 ports are open at the MX hosts
+// or the domain host, a service is running when any of them accepts the connection within ConnectTimeout.
+// The ports are dialed directly, or via the custom dialer, see EnableCustomDialer, but never via the proxy.
+func (v *Verifier) CheckMailServices(domain string) (*MailServices, error) {
+	v = v.snapshot()
+	domain = DomainToASCII(domain)
+
+	records, _, err := v.resolveMX(domain)
+	if err != nil && !isNotFoundDNSError(err) {
+		return nil, err
+	}
+	return v.checkMailServices(domain, records), nil
+}
+
+// checkMailServices dials the service ports of the MX hosts and the domain host concurrently
+func (v *Verifier) checkMailServices(domain string, records []*net.MX) *MailServices {
+	if services, ok := v.domainCache.mailServices(domain); ok {
+		return services
+	}
+
+	hosts := []string{domain}
+	for _, r := range records {
+		// The null MX (RFC 7505) names no host
+		if r.Host != "." && r.Host != "" && r.Host != domain+"." {
+			hosts = append(hosts, r.Host)
+		}
+	}
+
+	var ret MailServices
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		for port, open := range map[string]*bool{submissionPort: &ret.Submission, imapsPort: &ret.IMAP, pop3sPort: &ret.POP3} {
+			wg.Add(1)
+			go func(addr string, open *bool) {
+				defer wg.Done()
+				if v.dialService(addr) {
+					mu.Lock()
+					*open = true
+					mu.Unlock()
+				}
+			}(hostPort(host, port), open)
+		}
+	}
+	wg.Wait()
+
+	// The ports not dialed due to the exhausted budget of Verify are not known to be closed
+	if !v.budgetExceeded() {
+		v.domainCache.setMailServices(domain, &ret)
+	}
+	return &ret
+}
+
+// dialService reports whether the address accepts the connection
+func (v *Verifier) dialService(addr string) bool {
+	dial := func() (net.Conn, error) {
+		d := net.Dialer{Timeout: v.connectTimeout}
+		return d.DialContext(v.context(), v.dialNetwork, addr)
+	}
+	if v.dialerProvider != nil {
+		dial = v.dialerProvider.MakeDial(v.dialNetwork, addr)
+	}
+
+	conn, err := dial()
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
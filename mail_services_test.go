@@ -0,0 +1,64 @@
+package emailverifier
+
+import (
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// portDialer dials the mock servers by the port, the other ports refuse the connection
+type portDialer map[string]string
+
+func (d portDialer) MakeDial(network, addr string) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		_, port, _ := net.SplitHostPort(addr)
+		if target, ok := d[port]; ok {
+			return net.Dial(network, target)
+		}
+		return nil, syscall.ECONNREFUSED
+	}
+}
+
+func TestCheckMailServices(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	verifier := NewVerifier().
+		EnableCustomDialer(portDialer{imapsPort: srv.ln.Addr().String()}).
+		EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+			"example.test": {{Host: "mx.example.test.", Pref: 10}},
+		}})
+
+	services, err := verifier.CheckMailServices("example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, &MailServices{IMAP: true}, services)
+
+	// The domain without MX records is probed at the domain host
+	services, err = verifier.CheckMailServices("nomx.test")
+	assert.NoError(t, err)
+	assert.Equal(t, &MailServices{IMAP: true}, services)
+
+	services, err = verifier.EnableCustomDialer(portDialer{}).CheckMailServices("example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, &MailServices{}, services)
+}
+
+func TestVerify_MailServices(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	verifier := NewVerifier().
+		EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+			"example.test": {{Host: "mx.example.test.", Pref: 10}},
+		}}).
+		EnableCustomDialer(portDialer{submissionPort: srv.ln.Addr().String(), pop3sPort: srv.ln.Addr().String()})
+
+	ret, err := verifier.Verify("john@example.test")
+	assert.NoError(t, err)
+	assert.Nil(t, ret.MailServices)
+	assert.Equal(t, 50, ret.Score)
+
+	// The signal is collected even though the SMTP check fails
+	ret, err = verifier.EnableSMTPCheck().EnableMailServicesCheck().Verify("john@example.test")
+	assert.Error(t, err)
+	assert.Equal(t, &MailServices{Submission: true, POP3: true}, ret.MailServices)
+	assert.Equal(t, 55, ret.Score)
+}
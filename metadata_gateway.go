@@ -0,0 +1,24 @@
+package emailverifier
+
+// gatewayMXDomains are the domains of the MX hosts of the mail security gateways, which accept
+// any recipient and filter the mail later
+var gatewayMXDomains = []string{
+	"pphosted.com",          // Proofpoint
+	"ppe-hosted.com",        // Proofpoint Essentials
+	"mimecast.com",          // Mimecast
+	"mimecast.co.za",        // Mimecast South Africa
+	"mimecast-offshore.com", // Mimecast
+	"barracudanetworks.com", // Barracuda
+	"messagelabs.com",       // Broadcom Email Security.cloud
+	"iphmx.com",             // Cisco Secure Email
+	"mailcontrol.com",       // Forcepoint
+	"trendmicro.com",        // Trend Micro Email Security
+	"trendmicro.eu",         // Trend Micro Email Security
+	"sophos.com",            // Sophos Email
+	"mailguard.com.au",      // MailGuard
+	"spamexperts.com",       // SpamExperts
+	"antispamcloud.com",     // SpamExperts
+	"mxthunder.net",         // SpamHero
+	"electric.net",          // Electric Mail
+	"hornetsecurity.com",    // Hornetsecurity
+}
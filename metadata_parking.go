@@ -0,0 +1,18 @@
+package emailverifier
+
+// parkingHostDomains are the domains of the name servers and MX hosts of the domain parking providers,
+// the parked domains accept mail that goes nowhere
+var parkingHostDomains = []string{
+	"sedoparking.com",         // Sedo
+	"parkingcrew.net",         // ParkingCrew
+	"bodis.com",               // Bodis
+	"above.com",               // Above
+	"parklogic.com",           // ParkLogic
+	"dan.com",                 // Dan.com
+	"afternic.com",            // Afternic
+	"hugedomains.com",         // HugeDomains
+	"undeveloped.com",         // Undeveloped
+	"uniregistrymarket.link",  // Uniregistry Market
+	"parking.reg.ru",          // REG.RU
+	"domainparkingserver.net", // Domain Parking Server
+}
@@ -0,0 +1,18 @@
+package emailverifier
+
+// knownProviders are the big email providers whose behavior is known, keyed by domain
+var knownProviders = map[string]KnownProvider{
+	"gmail.com":      {Name: "gmail", API: GMAIL, CatchAll: CatchAllNo},
+	"googlemail.com": {Name: "gmail", API: GMAIL, CatchAll: CatchAllNo},
+	"outlook.com":    {Name: "outlook", API: OUTLOOK, CatchAll: CatchAllNo},
+	"hotmail.com":    {Name: "outlook", API: OUTLOOK, CatchAll: CatchAllNo},
+	"live.com":       {Name: "outlook", API: OUTLOOK, CatchAll: CatchAllNo},
+	"msn.com":        {Name: "outlook", API: OUTLOOK, CatchAll: CatchAllNo},
+	// Yahoo accepts any recipient during the SMTP conversation
+	"yahoo.com":  {Name: "yahoo", API: YAHOO, CatchAll: CatchAllYes},
+	"ymail.com":  {Name: "yahoo", API: YAHOO, CatchAll: CatchAllYes},
+	"aol.com":    {Name: "yahoo", API: YAHOO, CatchAll: CatchAllYes},
+	"icloud.com": {Name: "icloud", CatchAll: CatchAllNo},
+	"me.com":     {Name: "icloud", CatchAll: CatchAllNo},
+	"mac.com":    {Name: "icloud", CatchAll: CatchAllNo},
+}
@@ -53,3 +53,63 @@ var suggestionTopLevelDomains = map[string]bool{
 	"hu":     true,
 	"uk":     true,
 }
+
+// suggestionLanguageDomains are popular email providers preferred in the suggestions for a locale, by ISO 639-1 language
+var suggestionLanguageDomains = map[string][]string{
+	"cs": {"seznam.cz", "email.cz", "centrum.cz", "atlas.cz", "post.cz"},
+	"de": {"gmx.de", "web.de", "t-online.de", "freenet.de", "gmx.net", "posteo.de", "arcor.de"},
+	"es": {"terra.es", "telefonica.net", "movistar.es"},
+	"fr": {"orange.fr", "free.fr", "laposte.net", "sfr.fr", "wanadoo.fr", "neuf.fr"},
+	"it": {"libero.it", "virgilio.it", "tiscali.it", "alice.it", "tim.it"},
+	"ja": {"yahoo.co.jp", "docomo.ne.jp", "ezweb.ne.jp", "softbank.ne.jp", "nifty.com"},
+	"ko": {"naver.com", "daum.net", "hanmail.net", "nate.com"},
+	"nl": {"ziggo.nl", "kpnmail.nl", "hetnet.nl", "planet.nl", "home.nl"},
+	"pl": {"wp.pl", "onet.pl", "o2.pl", "interia.pl", "op.pl"},
+	"pt": {"sapo.pt", "uol.com.br", "bol.com.br", "terra.com.br"},
+	"ru": {"yandex.ru", "mail.ru", "rambler.ru", "bk.ru", "list.ru", "inbox.ru", "ya.ru"},
+	"tr": {"hotmail.com.tr", "mynet.com", "superonline.com"},
+	"uk": {"ukr.net", "i.ua", "meta.ua"},
+	"zh": {"qq.com", "163.com", "126.com", "sina.com", "sohu.com", "yeah.net"},
+}
+
+// suggestionRegionDomains are popular email providers preferred in the suggestions for a locale, by ISO 3166-1 region
+var suggestionRegionDomains = map[string][]string{
+	"at": {"gmx.at", "aon.at", "chello.at"},
+	"au": {"bigpond.com", "optusnet.com.au", "yahoo.com.au"},
+	"be": {"skynet.be", "telenet.be"},
+	"br": {"uol.com.br", "bol.com.br", "terra.com.br", "ig.com.br"},
+	"ch": {"bluewin.ch", "gmx.ch", "hispeed.ch"},
+	"gb": {"btinternet.com", "sky.com", "virginmedia.com", "talktalk.net", "yahoo.co.uk"},
+	"in": {"rediffmail.com", "yahoo.co.in"},
+	"tw": {"yahoo.com.tw", "hinet.net"},
+}
+
+// suggestionTLDTypos are common fat-finger typos of the popular top level domains, the ones which are valid
+// top level domains themselves (e.g. "co") are corrected only for the known domains, e.g. gmail.co
+var suggestionTLDTypos = map[string]string{
+	"con":  "com",
+	"cmo":  "com",
+	"ocm":  "com",
+	"comm": "com",
+	"coom": "com",
+	"comn": "com",
+	"vom":  "com",
+	"xom":  "com",
+	"cpm":  "com",
+	"cim":  "com",
+	"clm":  "com",
+	"c0m":  "com",
+	"cm":   "com",
+	"co":   "com",
+	"om":   "com",
+	"nte":  "net",
+	"ner":  "net",
+	"nrt":  "net",
+	"nett": "net",
+	"ne":   "net",
+	"ogr":  "org",
+	"orh":  "org",
+	"rog":  "org",
+	"prg":  "org",
+	"orgg": "org",
+}
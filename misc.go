@@ -1,21 +1,141 @@
 package emailverifier
 
 import (
+	"errors"
+	"net"
 	"strings"
 )
 
-// IsRoleAccount checks if username is a role-based account
+// ErrDisposableCheckDisabled is returned when the disposable check is used without a disposable repo,
+// see EnableDisposableCheck
+var ErrDisposableCheckDisabled = errors.New("disposable check is not enabled")
+
+// builtinRoleAccountKeys holds the keys of the built-in role accounts
+var builtinRoleAccountKeys = func() map[string]bool {
+	keys := make(map[string]bool, len(roleAccounts))
+	for name := range roleAccounts {
+		keys[roleAccountKey(name)] = true
+	}
+	return keys
+}()
+
+// IsRoleAccount checks if username is a role-based account. The match is case-insensitive
+// and ignores the separators, i.e. "no-reply", "no.reply" and "noreply" are equivalent
 func (v *Verifier) IsRoleAccount(username string) bool {
-	return roleAccounts[strings.ToLower(username)]
+	return v.snapshot().isRoleAccount(username)
+}
+
+// isRoleAccount checks if username is a role-based account, see IsRoleAccount
+func (v *Verifier) isRoleAccount(username string) bool {
+	key := roleAccountKey(username)
+	if !v.roleAccountsReplaced && builtinRoleAccountKeys[key] {
+		return true
+	}
+	return v.roleAccounts.has(key)
 }
 
-// IsFreeDomain checks if domain is a free domain
+// IsRoleAccountName is like IsRoleAccount, but consults the built-in role accounts only,
+// so no Verifier is needed for a one-off check
+func IsRoleAccountName(username string) bool {
+	return builtinRoleAccountKeys[roleAccountKey(username)]
+}
+
+// roleAccountKey normalizes the username for the role account matching
+func roleAccountKey(username string) string {
+	return strings.NewReplacer("-", "", ".", "", "_", "").Replace(strings.ToLower(username))
+}
+
+// roleAccountKeys normalizes the usernames for the role account matching
+func roleAccountKeys(names []string) []string {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = roleAccountKey(name)
+	}
+	return keys
+}
+
+// IsFreeDomain checks if domain is a free domain, consulting the built-in list,
+// the domains added via AddFreeDomains and the free domain provider
 func (v *Verifier) IsFreeDomain(domain string) bool {
-	return freeDomains[domain]
+	return v.snapshot().isFreeDomain(domain)
 }
 
-// IsDisposable checks if domain is a disposable domain
+// isFreeDomain checks if domain is a free domain, see IsFreeDomain
+func (v *Verifier) isFreeDomain(domain string) bool {
+	if IsFreeEmailDomain(domain) || v.freeDomains.has(domain) {
+		return true
+	}
+	return v.freeDomainProvider != nil && v.freeDomainProvider.IsFreeDomain(domain)
+}
+
+// IsFreeEmailDomain is like IsFreeDomain, but consults the built-in free domains only,
+// so no Verifier is needed for a one-off check. The match is case-insensitive.
+func IsFreeEmailDomain(domain string) bool {
+	return freeDomains[strings.ToLower(domain)]
+}
+
+// IsDisposable checks if domain is a disposable domain. When the subdomain matching is enabled,
+// subdomains of a disposable domain, e.g. abc.mailinator.com, are disposable too.
+// It returns false when the disposable check is not enabled, see IsDisposableE.
 func (v *Verifier) IsDisposable(domain string) bool {
+	disposable, _ := v.snapshot().checkDisposable(domain)
+	return disposable
+}
+
+// IsDisposableE is like IsDisposable, but returns ErrDisposableCheckDisabled
+// when the disposable check is not enabled
+func (v *Verifier) IsDisposableE(domain string) (bool, error) {
+	return v.snapshot().checkDisposable(domain)
+}
+
+// checkDisposable checks if domain is a disposable domain, see IsDisposableE
+func (v *Verifier) checkDisposable(domain string) (bool, error) {
+	if v.disposableRepo == nil {
+		return false, ErrDisposableCheckDisabled
+	}
+	return v.isDisposable(domain), nil
+}
+
+// isDisposable checks if domain is a disposable domain in the disposable repo
+func (v *Verifier) isDisposable(domain string) bool {
 	domain = DomainToASCII(domain)
-	return v.disposableRepo.IsDomainDisposable(domain)
+	if v.disposableRepo.IsDomainDisposable(domain) {
+		return true
+	}
+	if !v.subdomainMatchEnabled {
+		return false
+	}
+
+	// Walks up the label hierarchy, the top level domain alone is never checked
+	for i := strings.IndexByte(domain, '.'); i >= 0; i = strings.IndexByte(domain, '.') {
+		domain = domain[i+1:]
+		if !strings.Contains(domain, ".") {
+			break
+		}
+		if v.disposableRepo.IsDomainDisposable(domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDisposableByMX checks if any of the MX hosts of the domain is a disposable MX host,
+// which catches the disposable providers rotating through throwaway domains not listed yet
+func (v *Verifier) IsDisposableByMX(domain string) (bool, error) {
+	v = v.snapshot()
+	records, err := v.lookupMX(DomainToASCII(domain))
+	if err != nil {
+		return false, err
+	}
+	return v.hasDisposableMX(records), nil
+}
+
+// hasDisposableMX checks if any of the MX records points to a disposable MX host
+func (v *Verifier) hasDisposableMX(records []*net.MX) bool {
+	for _, r := range records {
+		if v.disposableMXHosts.has(strings.TrimSuffix(r.Host, ".")) {
+			return true
+		}
+	}
+	return false
 }
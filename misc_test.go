@@ -1,6 +1,7 @@
 package emailverifier
 
 import (
+	"net"
 	"sync"
 	"testing"
 
@@ -8,24 +9,59 @@ import (
 )
 
 type disposableRepo struct {
-	domains sync.Map
+	mu      sync.RWMutex
+	domains map[string]struct{}
 }
 
 func newDisposableRepo() *disposableRepo {
-	return &disposableRepo{}
+	return &disposableRepo{domains: make(map[string]struct{})}
 }
 
 func (m *disposableRepo) AddDisposableDomains(domains []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	for _, d := range domains {
-		m.domains.Store(d, struct{}{})
+		m.domains[d] = struct{}{}
 	}
 }
 
 func (m *disposableRepo) IsDomainDisposable(domain string) bool {
-	_, found := m.domains.Load(domain)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, found := m.domains[domain]
 	return found
 }
 
+func (m *disposableRepo) RemoveDisposableDomains(domains []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range domains {
+		delete(m.domains, d)
+	}
+}
+
+func (m *disposableRepo) ReplaceDisposableDomains(domains []string) {
+	replaced := make(map[string]struct{}, len(domains))
+	for _, d := range domains {
+		replaced[d] = struct{}{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.domains = replaced
+}
+
+func (m *disposableRepo) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.domains)
+}
+
 var verifier = NewVerifier().EnableSMTPCheck().EnableDisposableCheck(newDisposableRepo())
 
 func TestIsFreeDomain_True(t *testing.T) {
@@ -69,3 +105,161 @@ func TestIsRoleAccount_False(t *testing.T) {
 	isRoleAccount := verifier.IsRoleAccount(username)
 	assert.False(t, isRoleAccount)
 }
+
+func TestIsFreeEmailDomain(t *testing.T) {
+	assert.True(t, IsFreeEmailDomain("gmail.com"))
+	assert.True(t, IsFreeEmailDomain("GMail.com"))
+	assert.False(t, IsFreeEmailDomain("github.com"))
+	// The domains added to a verifier are not consulted
+	NewVerifier().AddFreeDomains([]string{"webmail.example"})
+	assert.False(t, IsFreeEmailDomain("webmail.example"))
+}
+
+func TestIsRoleAccountName(t *testing.T) {
+	assert.True(t, IsRoleAccountName("administrator"))
+	assert.True(t, IsRoleAccountName("No_Reply"))
+	assert.False(t, IsRoleAccountName("normal_user"))
+}
+
+type freeDomainProvider map[string]bool
+
+func (p freeDomainProvider) IsFreeDomain(domain string) bool {
+	return p[domain]
+}
+
+func TestIsFreeDomain_AddFreeDomains(t *testing.T) {
+	v := NewVerifier().AddFreeDomains([]string{"Webmail.Example"})
+
+	assert.True(t, v.IsFreeDomain("webmail.example"))
+	assert.True(t, v.IsFreeDomain("gmail.com"))
+	assert.False(t, v.IsFreeDomain("github.com"))
+	assert.False(t, NewVerifier().IsFreeDomain("webmail.example"))
+}
+
+func TestIsFreeDomain_FreeDomainProvider(t *testing.T) {
+	v := NewVerifier().EnableFreeDomainProvider(freeDomainProvider{"regional.example": true})
+
+	assert.True(t, v.IsFreeDomain("regional.example"))
+	assert.True(t, v.IsFreeDomain("gmail.com"))
+	assert.False(t, v.IsFreeDomain("github.com"))
+
+	v.DisableFreeDomainProvider()
+	assert.False(t, v.IsFreeDomain("regional.example"))
+}
+
+func TestIsRoleAccount_SeparatorsIgnored(t *testing.T) {
+	assert.True(t, verifier.IsRoleAccount("No_Reply"))
+	assert.True(t, verifier.IsRoleAccount("noreply"))
+	assert.True(t, verifier.IsRoleAccount("no.reply"))
+}
+
+func TestIsRoleAccount_AddRoleAccounts(t *testing.T) {
+	v := NewVerifier().AddRoleAccounts([]string{"Abuse-Team"})
+
+	assert.True(t, v.IsRoleAccount("abuse-team"))
+	assert.True(t, v.IsRoleAccount("ABUSE_TEAM"))
+	assert.True(t, v.IsRoleAccount("admin"))
+	assert.False(t, NewVerifier().IsRoleAccount("abuse-team"))
+}
+
+func TestIsRoleAccount_RoleAccountsReplaced(t *testing.T) {
+	v := NewVerifier().RoleAccounts([]string{"billing"})
+
+	assert.True(t, v.IsRoleAccount("Billing"))
+	assert.False(t, v.IsRoleAccount("admin"))
+
+	v.AddRoleAccounts([]string{"security"})
+	assert.True(t, v.IsRoleAccount("security"))
+	assert.True(t, v.IsRoleAccount("billing"))
+}
+
+func TestIsDisposableByMX(t *testing.T) {
+	v := NewVerifier().
+		EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+			"throwaway.example":  {{Host: "MX.Disposable.example.", Pref: 10}},
+			"legitimate.example": {{Host: "mx.legitimate.example.", Pref: 10}},
+		}}).
+		AddDisposableMXHosts([]string{"mx.disposable.example."})
+
+	disposable, err := v.IsDisposableByMX("throwaway.example")
+	assert.NoError(t, err)
+	assert.True(t, disposable)
+
+	disposable, err = v.IsDisposableByMX("legitimate.example")
+	assert.NoError(t, err)
+	assert.False(t, disposable)
+
+	_, err = v.IsDisposableByMX("unknown.example")
+	assert.Error(t, err)
+}
+
+func TestCheckEmail_DisposableByMX(t *testing.T) {
+	v := NewVerifier().
+		EnableDisposableCheck(newDisposableRepo()).
+		EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+			"throwaway.example": {{Host: "mx.disposable.example.", Pref: 10}},
+		}}).
+		AddDisposableMXHosts([]string{"mx.disposable.example"})
+
+	ret, err := v.Verify("someone@throwaway.example")
+	assert.NoError(t, err)
+	assert.True(t, ret.Disposable)
+	assert.True(t, ret.HasMxRecords)
+}
+
+func TestIsDisposable_Subdomain(t *testing.T) {
+	repo := newDisposableRepo()
+	repo.AddDisposableDomains([]string{"mailinator.example"})
+	v := NewVerifier().EnableDisposableCheck(repo)
+
+	assert.True(t, v.IsDisposable("mailinator.example"))
+	assert.False(t, v.IsDisposable("abc.mailinator.example"))
+
+	v.EnableDisposableSubdomainMatch()
+	assert.True(t, v.IsDisposable("mailinator.example"))
+	assert.True(t, v.IsDisposable("abc.mailinator.example"))
+	assert.True(t, v.IsDisposable("x.y.abc.mailinator.example"))
+	assert.False(t, v.IsDisposable("mailinator.example.org"))
+	assert.False(t, v.IsDisposable("notmailinator.example"))
+	assert.False(t, v.IsDisposable("example"))
+
+	v.DisableDisposableSubdomainMatch()
+	assert.False(t, v.IsDisposable("abc.mailinator.example"))
+}
+
+func TestIsDisposable_SubdomainNeverMatchesTLD(t *testing.T) {
+	repo := newDisposableRepo()
+	repo.AddDisposableDomains([]string{"example"})
+	v := NewVerifier().EnableDisposableCheck(repo).EnableDisposableSubdomainMatch()
+
+	assert.False(t, v.IsDisposable("abc.example"))
+}
+
+func TestIsDisposable_CheckDisabled(t *testing.T) {
+	v := NewVerifier()
+
+	assert.False(t, v.IsDisposable("dbbd8.club"))
+	disposable, err := v.IsDisposableE("dbbd8.club")
+	assert.False(t, disposable)
+	assert.Equal(t, ErrDisposableCheckDisabled, err)
+}
+
+func TestIsDisposableE(t *testing.T) {
+	repo := newDisposableRepo()
+	repo.AddDisposableDomains([]string{"throwaway.example"})
+	v := NewVerifier().EnableDisposableCheck(repo)
+
+	disposable, err := v.IsDisposableE("throwaway.example")
+	assert.NoError(t, err)
+	assert.True(t, disposable)
+}
+
+func TestCheckEmail_DisposableCheckDisabled(t *testing.T) {
+	v := NewVerifier().EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+		"example.test": {{Host: "mx.example.test.", Pref: 10}},
+	}})
+
+	ret, err := v.Verify("someone@example.test")
+	assert.NoError(t, err)
+	assert.False(t, ret.Disposable)
+}
@@ -1,25 +1,124 @@
 package emailverifier
 
 import (
-	"context"
+	"errors"
 	"net"
+	"time"
 )
 
 // Mx is detail about the Mx host
 type Mx struct {
 	HasMXRecord bool      // whether has 1 or more MX record
 	Records     []*net.MX // represent DNS MX records
+	Implicit    bool      // whether the record is the implicit MX, i.e. the domain itself
 }
 
 // CheckMX will return the DNS MX records for the given domain name sorted by preference.
+// When the implicit MX is enabled and the domain has no MX records but an A/AAAA record,
+// the domain itself is returned as the only MX record with preference 0.
 func (v *Verifier) CheckMX(domain string) (*Mx, error) {
+	return v.snapshot().checkMX(domain)
+}
+
+// checkMX returns the DNS MX records for the given domain name, see CheckMX
+func (v *Verifier) checkMX(domain string) (*Mx, error) {
 	domain = DomainToASCII(domain)
-	mx, err := v.mxResolver.LookupMX(context.Background(), domain)
+	mx, implicit, err := v.resolveMX(domain)
 	if err != nil && len(mx) == 0 {
 		return nil, err
 	}
 	return &Mx{
 		HasMXRecord: len(mx) > 0,
 		Records:     mx,
+		Implicit:    implicit,
 	}, nil
 }
+
+// resolveMX resolves the MX records of the domain, falling back to the implicit MX when enabled:
+// per RFC 5321 (section 5.1), a domain without MX records but with an A/AAAA record
+// is treated as if it had an MX record pointing to the domain itself with preference 0
+func (v *Verifier) resolveMX(domain string) ([]*net.MX, bool, error) {
+	records, err := v.lookupMX(domain)
+	if len(records) > 0 || !v.implicitMXEnabled {
+		return records, false, err
+	}
+
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return records, false, err
+	}
+
+	addrs, hostErr := v.lookupHost(domain)
+	if hostErr != nil || len(addrs) == 0 {
+		return records, false, err
+	}
+
+	return []*net.MX{{Host: domain + ".", Pref: 0}}, true, nil
+}
+
+// lookupMX resolves the MX records of the domain, consulting the MX and domain caches when enabled
+func (v *Verifier) lookupMX(domain string) ([]*net.MX, error) {
+	if v.mxCache != nil {
+		if records, ok := v.mxCache.get(domain); ok {
+			return records, nil
+		}
+	}
+
+	records, ttl, err := v.queryMX(domain)
+	backoff := v.mxLookupBackoff
+	for i := 1; i < v.mxLookupAttempts && isTemporaryDNSError(err); i++ {
+		v.sleep(backoff)
+		backoff *= 2
+		records, ttl, err = v.queryMX(domain)
+	}
+
+	if err == nil && len(records) > 0 {
+		if v.mxCache != nil {
+			v.mxCache.set(domain, records, ttl)
+		}
+	}
+	return records, err
+}
+
+// queryMX queries the resolver for the MX records of the domain
+func (v *Verifier) queryMX(domain string) ([]*net.MX, time.Duration, error) {
+	var records []*net.MX
+	var ttl time.Duration
+	var err error
+	start := time.Now()
+	if r, ok := v.mxResolver.(ttlMXResolver); ok {
+		records, ttl, err = r.LookupMXWithTTL(v.context(), domain)
+	} else {
+		records, err = v.mxResolver.LookupMX(v.context(), domain)
+	}
+	dur := time.Since(start)
+	v.observer().OnMXLookup(domain, dur, err)
+	v.debug("mx lookup", "domain", domain, "records", len(records), "duration", dur, "error", err)
+	return records, ttl, err
+}
+
+// isTemporaryDNSError reports whether the DNS lookup failed temporarily, e.g. by SERVFAIL or a timeout,
+// so that repeating it may succeed, unlike for a domain which does not exist
+func isTemporaryDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		return false
+	}
+	return !dnsErr.IsNotFound && (dnsErr.IsTemporary || dnsErr.IsTimeout)
+}
+
+// isNotFoundDNSError reports whether the DNS lookup found no records of the name
+func isNotFoundDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// domainNotFound checks if the domain without MX records does not exist at all, i.e. it has neither
+// an A/AAAA nor an NS record. A failed lookup is not conclusive, so the domain is assumed to exist then.
+func (v *Verifier) domainNotFound(domain string) bool {
+	if _, err := v.lookupHost(domain); !isNotFoundDNSError(err) {
+		return false
+	}
+	_, err := v.lookupNS(domain)
+	return isNotFoundDNSError(err)
+}
@@ -0,0 +1,70 @@
+package emailverifier
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// mxCache is a goroutine-safe in-memory cache of MX records keyed by domain
+type mxCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration // maximum lifetime of an entry
+	entries map[string]mxCacheEntry
+}
+
+type mxCacheEntry struct {
+	records []*net.MX
+	expires time.Time
+}
+
+// newMXCache creates a new MX cache whose entries live at most ttl
+func newMXCache(ttl time.Duration) *mxCache {
+	return &mxCache{
+		ttl:     ttl,
+		entries: map[string]mxCacheEntry{},
+	}
+}
+
+// get returns the cached MX records of the domain, if they have not expired yet
+func (c *mxCache) get(domain string) ([]*net.MX, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[domain]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(e.expires) {
+		c.mu.Lock()
+		if e, ok = c.entries[domain]; ok && time.Now().After(e.expires) {
+			delete(c.entries, domain)
+		}
+		c.mu.Unlock()
+		return nil, false
+	}
+	return e.records, true
+}
+
+// set stores the MX records of the domain, answerTTL is the TTL of the DNS answer (if known)
+// and bounds the lifetime of the entry when it is shorter than the configured TTL
+func (c *mxCache) set(domain string, records []*net.MX, answerTTL time.Duration) {
+	ttl := c.ttl
+	if answerTTL > 0 && answerTTL < ttl {
+		ttl = answerTTL
+	}
+
+	c.mu.Lock()
+	c.entries[domain] = mxCacheEntry{
+		records: records,
+		expires: time.Now().Add(ttl),
+	}
+	c.mu.Unlock()
+}
+
+// clear removes all the cached entries
+func (c *mxCache) clear() {
+	c.mu.Lock()
+	c.entries = map[string]mxCacheEntry{}
+	c.mu.Unlock()
+}
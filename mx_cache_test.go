@@ -0,0 +1,65 @@
+package emailverifier
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMXCacheOK_GetSet(t *testing.T) {
+	c := newMXCache(time.Minute)
+	records := []*net.MX{{Host: "mx.example.com.", Pref: 10}}
+
+	_, ok := c.get("example.com")
+	assert.False(t, ok)
+
+	c.set("example.com", records, 0)
+	actual, ok := c.get("example.com")
+	assert.True(t, ok)
+	assert.Equal(t, records, actual)
+}
+
+func TestMXCacheOK_Expired(t *testing.T) {
+	c := newMXCache(time.Millisecond)
+	c.set("example.com", []*net.MX{{Host: "mx.example.com."}}, 0)
+
+	time.Sleep(5 * time.Millisecond)
+	_, ok := c.get("example.com")
+	assert.False(t, ok)
+	assert.Empty(t, c.entries)
+}
+
+func TestMXCacheOK_AnswerTTLIsUpperBound(t *testing.T) {
+	c := newMXCache(time.Hour)
+	c.set("example.com", []*net.MX{{Host: "mx.example.com."}}, time.Millisecond)
+
+	time.Sleep(5 * time.Millisecond)
+	_, ok := c.get("example.com")
+	assert.False(t, ok)
+}
+
+func TestMXCacheOK_Clear(t *testing.T) {
+	c := newMXCache(time.Minute)
+	c.set("example.com", []*net.MX{{Host: "mx.example.com."}}, 0)
+
+	c.clear()
+	_, ok := c.get("example.com")
+	assert.False(t, ok)
+}
+
+func TestCheckMXOK_FromCache(t *testing.T) {
+	verifier := NewVerifier().EnableMXCache(time.Minute)
+	records := []*net.MX{{Host: "mx.example.test.", Pref: 10}}
+	verifier.mxCache.set("example.test", records, 0)
+
+	mx, err := verifier.CheckMX("example.test")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+	assert.Equal(t, records, mx.Records)
+
+	verifier.ClearMXCache()
+	_, ok := verifier.mxCache.get("example.test")
+	assert.False(t, ok)
+}
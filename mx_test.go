@@ -1,7 +1,10 @@
 package emailverifier
 
 import (
+	"context"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,3 +24,96 @@ func TestCheckNoMxOK(t *testing.T) {
 	assert.Nil(t, mx)
 	assert.Error(t, err, ErrNoSuchHost)
 }
+
+func TestCheckMXOK_ImplicitMX(t *testing.T) {
+	resolver := &mockResolver{hosts: map[string][]string{"example.com": {"2001:db8::25"}}}
+	verifier := NewVerifier().EnableMXResolver(resolver).EnableImplicitMX()
+
+	mx, err := verifier.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+	assert.True(t, mx.Implicit)
+	assert.Equal(t, []*net.MX{{Host: "example.com.", Pref: 0}}, mx.Records)
+}
+
+func TestCheckMXFailed_ImplicitMXWithoutAddress(t *testing.T) {
+	verifier := NewVerifier().EnableMXResolver(&mockResolver{}).EnableImplicitMX()
+
+	mx, err := verifier.CheckMX("example.com")
+	assert.Nil(t, mx)
+	assert.Error(t, err)
+}
+
+func TestCheckMXFailed_ImplicitMXDisabled(t *testing.T) {
+	resolver := &mockResolver{hosts: map[string][]string{"example.com": {"192.0.2.25"}}}
+	verifier := NewVerifier().EnableMXResolver(resolver)
+
+	mx, err := verifier.CheckMX("example.com")
+	assert.Nil(t, mx)
+	assert.Error(t, err)
+}
+
+// flakyResolver fails the first lookups of every domain with the error before resolving it by the wrapped resolver
+type flakyResolver struct {
+	*mockResolver
+	failures int
+	err      error
+	lookups  int
+}
+
+func (r *flakyResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	r.lookups++
+	if r.lookups <= r.failures {
+		return nil, r.err
+	}
+	return r.mockResolver.LookupMX(ctx, domain)
+}
+
+func TestCheckMXOK_RetryTemporaryError(t *testing.T) {
+	resolver := &flakyResolver{
+		mockResolver: &mockResolver{mx: map[string][]*net.MX{"example.com": {{Host: "mx.example.com.", Pref: 10}}}},
+		failures:     2,
+		err:          &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true},
+	}
+	verifier := NewVerifier().EnableMXResolver(resolver).MXLookupRetry(3, time.Millisecond)
+
+	mx, err := verifier.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+	assert.Equal(t, 3, resolver.lookups)
+}
+
+func TestCheckMXFailed_RetryExhausted(t *testing.T) {
+	resolver := &flakyResolver{
+		mockResolver: &mockResolver{},
+		failures:     5,
+		err:          &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true},
+	}
+	verifier := NewVerifier().EnableMXResolver(resolver).MXLookupRetry(3, time.Millisecond)
+
+	_, err := verifier.CheckMX("example.com")
+	assert.Error(t, err)
+	assert.Equal(t, 3, resolver.lookups)
+}
+
+func TestCheckMXFailed_NoRetryNotFound(t *testing.T) {
+	resolver := &flakyResolver{mockResolver: &mockResolver{}}
+	verifier := NewVerifier().EnableMXResolver(resolver).MXLookupRetry(3, time.Millisecond)
+
+	_, err := verifier.CheckMX("example.com")
+	assert.Error(t, err)
+	assert.Equal(t, 1, resolver.lookups)
+}
+
+func TestCheckMXFailed_RetryDisabledByDefault(t *testing.T) {
+	resolver := &flakyResolver{
+		mockResolver: &mockResolver{mx: map[string][]*net.MX{"example.com": {{Host: "mx.example.com.", Pref: 10}}}},
+		failures:     1,
+		err:          &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true},
+	}
+	verifier := NewVerifier().EnableMXResolver(resolver)
+
+	_, err := verifier.CheckMX("example.com")
+	assert.Error(t, err)
+	assert.Equal(t, 1, resolver.lookups)
+}
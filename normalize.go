@@ -0,0 +1,63 @@
+package emailverifier
+
+import (
+	"strings"
+)
+
+// normalizationRule describes how a free email provider treats the local part of its addresses
+type normalizationRule struct {
+	domain    string // canonical domain of the provider
+	stripDots bool   // dots in the local part are ignored
+	plusTag   bool   // everything after '+' in the local part is ignored
+}
+
+// normalizationRules are the normalization rules of known free email providers keyed by domain
+var normalizationRules = map[string]normalizationRule{
+	"gmail.com":      {domain: "gmail.com", stripDots: true, plusTag: true},
+	"googlemail.com": {domain: "gmail.com", stripDots: true, plusTag: true},
+	"outlook.com":    {domain: "outlook.com", plusTag: true},
+	"hotmail.com":    {domain: "hotmail.com", plusTag: true},
+	"live.com":       {domain: "live.com", plusTag: true},
+	"msn.com":        {domain: "msn.com", plusTag: true},
+	"icloud.com":     {domain: "icloud.com", plusTag: true},
+	"me.com":         {domain: "icloud.com", plusTag: true},
+	"mac.com":        {domain: "icloud.com", plusTag: true},
+	"fastmail.com":   {domain: "fastmail.com", plusTag: true},
+	"protonmail.com": {domain: "protonmail.com", plusTag: true},
+	"protonmail.ch":  {domain: "protonmail.com", plusTag: true},
+	"pm.me":          {domain: "protonmail.com", plusTag: true},
+	"yandex.ru":      {domain: "yandex.ru", plusTag: true},
+}
+
+// NormalizeEmail returns the canonical form of the email for deduplication:
+// the domain is lowercased and mapped from known aliases (e.g. googlemail.com to gmail.com),
+// plus-addressing is stripped for providers which support it and dots are removed for Gmail.
+// The original email is returned when it is invalid or its provider is unknown.
+func (v *Verifier) NormalizeEmail(email string) string {
+	return v.snapshot().normalizeEmail(email)
+}
+
+// normalizeEmail returns the canonical form of the email, see NormalizeEmail
+func (v *Verifier) normalizeEmail(email string) string {
+	syntax := v.ParseAddress(email)
+	if !syntax.Valid {
+		return email
+	}
+
+	rule, ok := normalizationRules[syntax.Domain]
+	if !ok || !v.isFreeDomain(syntax.Domain) {
+		return email
+	}
+
+	username := strings.ToLower(syntax.Username)
+	if rule.plusTag {
+		if i := strings.Index(username, "+"); i > 0 {
+			username = username[:i]
+		}
+	}
+	if rule.stripDots {
+		username = strings.Replace(username, ".", "", -1)
+	}
+
+	return username + "@" + rule.domain
+}
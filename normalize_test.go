@@ -0,0 +1,33 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	cases := []struct {
+		email    string
+		expected string
+	}{
+		{email: "John.Doe+news@Gmail.com", expected: "johndoe@gmail.com"},
+		{email: "j.o.h.n.doe@googlemail.com", expected: "johndoe@gmail.com"},
+		{email: "john.doe+shop@outlook.com", expected: "john.doe@outlook.com"},
+		{email: "john+tag@me.com", expected: "john@icloud.com"},
+		{email: "john+tag@pm.me", expected: "john@protonmail.com"},
+		{email: "John.Doe+tag@example.com", expected: "John.Doe+tag@example.com"},
+		{email: "invalid", expected: "invalid"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, verifier.NormalizeEmail(c.email), c.email)
+	}
+}
+
+func TestNormalizationRulesAreFreeDomains(t *testing.T) {
+	for domain, rule := range normalizationRules {
+		assert.True(t, freeDomains[domain], domain)
+		assert.True(t, freeDomains[rule.domain], rule.domain)
+	}
+}
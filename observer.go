@@ -0,0 +1,33 @@
+package emailverifier
+
+import "time"
+
+// Observer receives the outcome and duration of the network operations performed by the verifier,
+// e.g. to collect metrics. The callbacks are invoked synchronously, possibly from multiple goroutines.
+type Observer interface {
+	// OnMXLookup is called after the MX records of the domain are resolved (cache hits excluded)
+	OnMXLookup(domain string, dur time.Duration, err error)
+	// OnSMTPDial is called after connecting to the MX host
+	OnSMTPDial(host string, dur time.Duration, err error)
+	// OnSMTPCheck is called after the SMTP conversation with the MX host is finished
+	OnSMTPCheck(host string, dur time.Duration, err error)
+	// OnAPIVerify is called after the email is verified via the API of the provider, e.g. YAHOO
+	OnAPIVerify(provider string, dur time.Duration, err error)
+}
+
+// NopObserver is an Observer ignoring all the events,
+// embed it to implement only some of the callbacks
+type NopObserver struct{}
+
+func (NopObserver) OnMXLookup(string, time.Duration, error)  {}
+func (NopObserver) OnSMTPDial(string, time.Duration, error)  {}
+func (NopObserver) OnSMTPCheck(string, time.Duration, error) {}
+func (NopObserver) OnAPIVerify(string, time.Duration, error) {}
+
+// observer returns the observer of the verifier, NopObserver when none is set
+func (v *Verifier) observer() Observer {
+	if v.obs == nil {
+		return NopObserver{}
+	}
+	return v.obs
+}
@@ -0,0 +1,95 @@
+package emailverifier
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	NopObserver
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(event string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err != nil {
+		event += " error"
+	}
+	o.events = append(o.events, event)
+}
+
+func (o *recordingObserver) OnMXLookup(domain string, _ time.Duration, err error) {
+	o.record("mx "+domain, err)
+}
+
+func (o *recordingObserver) OnSMTPDial(host string, _ time.Duration, err error) {
+	o.record("dial "+host, err)
+}
+
+func (o *recordingObserver) OnSMTPCheck(host string, _ time.Duration, err error) {
+	o.record("check "+host, err)
+}
+
+func TestObserverOK(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@example.test")
+	obs := &recordingObserver{}
+
+	verifier := NewVerifier().EnableSMTPCheck().
+		EnableCustomDialer(srv.dialer()).
+		EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+			"example.test": {{Host: "mx.example.test.", Pref: 10}},
+		}}).
+		EnableMXCache(time.Minute).
+		SetObserver(obs)
+
+	_, err := verifier.CheckSMTP("example.test", "someone")
+	assert.NoError(t, err)
+	_, err = verifier.CheckSMTP("example.test", "nobody")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"mx example.test",
+		"dial mx.example.test.",
+		"check mx.example.test.",
+		"dial mx.example.test.",
+		"check mx.example.test. error",
+	}, obs.events)
+}
+
+func TestObserverOK_MXLookupFailed(t *testing.T) {
+	obs := &recordingObserver{}
+	verifier := NewVerifier().EnableMXResolver(&mockResolver{}).SetObserver(obs)
+
+	_, err := verifier.CheckMX("example.test")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"mx example.test error"}, obs.events)
+}
+
+type fakeAPIVerifier struct{}
+
+func (fakeAPIVerifier) isSupported(host string) bool { return true }
+
+func (fakeAPIVerifier) check(domain, username string) (*SMTP, error) {
+	return &SMTP{HostExists: true, Deliverable: true}, nil
+}
+
+func (o *recordingObserver) OnAPIVerify(provider string, _ time.Duration, err error) {
+	o.record("api "+provider, err)
+}
+
+func TestObserverOK_APIVerify(t *testing.T) {
+	obs := &recordingObserver{}
+	verifier := NewVerifier().SetObserver(obs)
+	verifier.apiVerifiers["FAKE"] = fakeAPIVerifier{}
+
+	_, err := verifier.CheckSMTPForMX([]string{"mx.example.test."}, "example.test", "someone")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api FAKE"}, obs.events)
+}
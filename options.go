@@ -0,0 +1,295 @@
+package emailverifier
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Options holds the configuration of a Verifier, an alternative to the fluent API
+// for the verifiers configured from a file. Start from DefaultOptions, as the zero
+// value disables the checks enabled by default, e.g. the catch-all check.
+type Options struct {
+	SMTPCheck        bool           `json:"smtp_check" yaml:"smtp_check"`
+	CatchAllCheck    bool           `json:"catch_all_check" yaml:"catch_all_check"`
+	CatchAllPolicy   CatchAllPolicy `json:"catch_all_policy" yaml:"catch_all_policy"` // "unknown" when empty
+	CatchAllProbes   int            `json:"catch_all_probes" yaml:"catch_all_probes"` // random addresses probed by the catch-all check, 1 when zero
+	SkipCatchAll     []string       `json:"skip_catch_all" yaml:"skip_catch_all"`     // domains not probed by the catch-all check
+	SMTPTLS          bool           `json:"smtp_tls" yaml:"smtp_tls"`
+	SMTPTranscript   bool           `json:"smtp_transcript" yaml:"smtp_transcript"`
+	VRFY             bool           `json:"vrfy" yaml:"vrfy"`                   // confirm the addresses by VRFY or EXPN before RCPT TO
+	HELOFallback     bool           `json:"helo_fallback" yaml:"helo_fallback"` // greet the servers rejecting EHLO by HELO
+	SMTPReuse        bool           `json:"smtp_reuse" yaml:"smtp_reuse"`       // reuse the SMTP connections across the checks of BatchVerify
+	FromEmail        string         `json:"from_email" yaml:"from_email"`
+	FromEmails       []string       `json:"from_emails" yaml:"from_emails"`             // candidates of FromEmail, the first one whose domain accepts mail is used
+	VerifyFromEmail  bool           `json:"verify_from_email" yaml:"verify_from_email"` // fail when the domain of FromEmail does not accept mail
+	HelloName        string         `json:"hello_name" yaml:"hello_name"`
+	SendingIP        string         `json:"sending_ip" yaml:"sending_ip"` // see CheckSendingIPReputation
+	Proxy            string         `json:"proxy" yaml:"proxy"`
+	ProxyPool        []string       `json:"proxy_pool" yaml:"proxy_pool"`
+	UserAgent        string         `json:"user_agent" yaml:"user_agent"`     // User-Agent of the outbound HTTP requests
+	HTTPHeaders      http.Header    `json:"http_headers" yaml:"http_headers"` // headers of the outbound HTTP requests
+	DialNetwork      string         `json:"dial_network" yaml:"dial_network"`
+	Timeout          Duration       `json:"timeout" yaml:"timeout"`                       // SMTP connection timeout, 30s when zero
+	VerifyTimeout    Duration       `json:"verify_timeout" yaml:"verify_timeout"`         // budget of the whole Verify, zero disables it
+	TarpitThreshold  Duration       `json:"tarpit_threshold" yaml:"tarpit_threshold"`     // delay of a reply flagging a tarpitting server, 10s when zero
+	MXParallelism    int            `json:"mx_parallelism" yaml:"mx_parallelism"`         // MX hosts dialed concurrently, see MXDialParallelism
+	GreylistRetry    Duration       `json:"greylist_retry" yaml:"greylist_retry"`         // delay of the greylist retry, zero disables it
+	SMTPRateLimit    float64        `json:"smtp_rate_limit" yaml:"smtp_rate_limit"`       // connections per second to every MX host, zero disables it
+	SMTPRateBurst    int            `json:"smtp_rate_burst" yaml:"smtp_rate_burst"`       // bursts of connections to every MX host
+	APIVerifiers     []string       `json:"api_verifiers" yaml:"api_verifiers"`           // "gmail", "outlook" and "yahoo"
+	APIFallback      bool           `json:"api_fallback" yaml:"api_fallback"`             // see EnableAPIFallback
+	KnownProviders   bool           `json:"known_providers" yaml:"known_providers"`       // see EnableKnownProviders
+	APIRetryAttempts int            `json:"api_retry_attempts" yaml:"api_retry_attempts"` // attempts of the API check refused due to the rate limit, see APIVerifierRetry
+	APIRetryBackoff  Duration       `json:"api_retry_backoff" yaml:"api_retry_backoff"`   // delay before the second attempt, doubled after each
+	MXCacheTTL       Duration       `json:"mx_cache_ttl" yaml:"mx_cache_ttl"`             // zero disables the MX cache
+	DomainCacheTTL   Duration       `json:"domain_cache_ttl" yaml:"domain_cache_ttl"`     // zero disables the domain cache
+	MXLookupAttempts int            `json:"mx_lookup_attempts" yaml:"mx_lookup_attempts"` // attempts of the MX lookup failing temporarily, see MXLookupRetry
+	MXLookupBackoff  Duration       `json:"mx_lookup_backoff" yaml:"mx_lookup_backoff"`   // delay before the second attempt, doubled after each
+	ImplicitMX       bool           `json:"implicit_mx" yaml:"implicit_mx"`
+	StrictSyntax     bool           `json:"strict_syntax" yaml:"strict_syntax"`
+	GravatarCheck    bool           `json:"gravatar_check" yaml:"gravatar_check"`
+	GravatarProfile  bool           `json:"gravatar_profile" yaml:"gravatar_profile"`
+	DMARCCheck       bool           `json:"dmarc_check" yaml:"dmarc_check"`
+	DomainAgeCheck   bool           `json:"domain_age_check" yaml:"domain_age_check"`
+	MailServiceCheck bool           `json:"mail_service_check" yaml:"mail_service_check"`
+
+	ResultCacheTTL            Duration `json:"result_cache_ttl" yaml:"result_cache_ttl"`                         // zero disables the result cache
+	ResultCacheUnreachableTTL Duration `json:"result_cache_unreachable_ttl" yaml:"result_cache_unreachable_ttl"` // ResultCacheTTL when zero
+	ResultCacheUnknownTTL     Duration `json:"result_cache_unknown_ttl" yaml:"result_cache_unknown_ttl"`         // ResultCacheTTL when zero
+
+	RejectPolicy        RejectPolicy `json:"reject_policy" yaml:"reject_policy"`                   // the addresses rejected without the network checks
+	Blocklist           []string     `json:"blocklist" yaml:"blocklist"`                           // addresses and domains rejected before any other check
+	Allowlist           []string     `json:"allowlist" yaml:"allowlist"`                           // addresses and domains accepted without the network checks
+	RoleAccountSMTPSkip bool         `json:"role_account_smtp_skip" yaml:"role_account_smtp_skip"` // role accounts are not probed via SMTP
+	GatewayMXHosts      []string     `json:"gateway_mx_hosts" yaml:"gateway_mx_hosts"`             // added to the built-in mail security gateways
+	GatewayDowngrade    bool         `json:"gateway_downgrade" yaml:"gateway_downgrade"`           // the addresses accepted by a gateway are unknown
+	ParkedCheck         bool         `json:"parked_check" yaml:"parked_check"`                     // detect the parked domains, see Result.Parked
+	ParkingHosts        []string     `json:"parking_hosts" yaml:"parking_hosts"`                   // added to the built-in parking providers
+
+	DomainSuggest          bool     `json:"domain_suggest" yaml:"domain_suggest"`
+	DomainSuggestThreshold float32  `json:"domain_suggest_threshold" yaml:"domain_suggest_threshold"` // 0.82 when zero
+	SuggestionDomains      []string `json:"suggestion_domains" yaml:"suggestion_domains"`
+	SuggestionLocale       string   `json:"suggestion_locale" yaml:"suggestion_locale"` // e.g. "de" or "de-AT"
+
+	FreeDomains  []string `json:"free_domains" yaml:"free_domains"`   // added to the built-in ones
+	RoleAccounts []string `json:"role_accounts" yaml:"role_accounts"` // added to the built-in ones
+
+	// DisposableRepo enables the disposable check, it can not be read from a file
+	DisposableRepo           DisposableRepo `json:"-" yaml:"-"`
+	AutoUpdateDisposable     Duration       `json:"auto_update_disposable" yaml:"auto_update_disposable"`       // update interval, zero disables it
+	DisposableReplace        bool           `json:"disposable_replace" yaml:"disposable_replace"`               // the automatic update replaces the domains
+	DisposableFetchTimeout   Duration       `json:"disposable_fetch_timeout" yaml:"disposable_fetch_timeout"`   // 5s when zero
+	DisposableMaxSize        int64          `json:"disposable_max_size" yaml:"disposable_max_size"`             // in bytes, 50MB when zero
+	DisposableFetchAttempts  int            `json:"disposable_fetch_attempts" yaml:"disposable_fetch_attempts"` // see DisposableFetchRetry
+	DisposableFetchBackoff   Duration       `json:"disposable_fetch_backoff" yaml:"disposable_fetch_backoff"`   // delay before the second attempt, doubled after each
+	DisposableSubdomainMatch bool           `json:"disposable_subdomain_match" yaml:"disposable_subdomain_match"`
+	DisposableMXHosts        []string       `json:"disposable_mx_hosts" yaml:"disposable_mx_hosts"`
+}
+
+// DefaultOptions returns the options of a Verifier created by NewVerifier
+func DefaultOptions() Options {
+	return Options{
+		CatchAllCheck:          true,
+		HELOFallback:           true,
+		CatchAllPolicy:         CatchAllPolicyUnknown,
+		FromEmail:              defaultFromEmail,
+		HelloName:              defaultHelloName,
+		DialNetwork:            "tcp",
+		Timeout:                Duration(smtpTimeout),
+		DomainSuggestThreshold: domainThreshold,
+	}
+}
+
+// NewVerifierWithOptions creates a Verifier configured by the options,
+// the verifier can be configured further via the fluent API
+func NewVerifierWithOptions(opts Options) (*Verifier, error) {
+	v := NewVerifier()
+
+	if opts.SMTPCheck {
+		v.EnableSMTPCheck()
+	}
+	if !opts.CatchAllCheck {
+		v.DisableCatchAllCheck()
+	}
+	if err := opts.CatchAllPolicy.validate(); err != nil {
+		return nil, err
+	}
+	v.CatchAllPolicy(opts.CatchAllPolicy).CatchAllProbeCount(opts.CatchAllProbes)
+	v.SkipCatchAllDomains(opts.SkipCatchAll)
+	if opts.SMTPTLS {
+		v.EnableSMTPTLS()
+	}
+	if opts.SMTPTranscript {
+		v.EnableSMTPTranscript()
+	}
+	if opts.VRFY {
+		v.EnableVRFY()
+	}
+	if !opts.HELOFallback {
+		v.DisableHELOFallback()
+	}
+	if opts.SMTPReuse {
+		v.EnableSMTPConnectionReuse()
+	}
+	if opts.FromEmail != "" {
+		v.FromEmail(opts.FromEmail)
+	}
+	if opts.HelloName != "" {
+		v.HelloName(opts.HelloName)
+	}
+	if opts.SendingIP != "" {
+		v.SendingIP(opts.SendingIP)
+	}
+	v.UserAgent(opts.UserAgent).HTTPHeaders(opts.HTTPHeaders)
+	v.Proxy(opts.Proxy).
+		ProxyPool(opts.ProxyPool).
+		DialNetwork(opts.DialNetwork).
+		ConnectTimeout(time.Duration(opts.Timeout)).
+		VerifyTimeout(time.Duration(opts.VerifyTimeout)).
+		TarpitThreshold(time.Duration(opts.TarpitThreshold)).
+		MXDialParallelism(opts.MXParallelism).
+		SMTPRateLimit(rate.Limit(opts.SMTPRateLimit), opts.SMTPRateBurst)
+	if opts.GreylistRetry > 0 {
+		v.EnableGreylistRetry(time.Duration(opts.GreylistRetry))
+	}
+	for _, name := range opts.APIVerifiers {
+		if err := v.EnableAPIVerifier(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.APIRetryAttempts > 1 {
+		v.APIVerifierRetry(opts.APIRetryAttempts, time.Duration(opts.APIRetryBackoff))
+	}
+	if opts.APIFallback {
+		v.EnableAPIFallback()
+	}
+	if opts.KnownProviders {
+		v.EnableKnownProviders()
+	}
+	if opts.MXCacheTTL > 0 {
+		v.EnableMXCache(time.Duration(opts.MXCacheTTL))
+	}
+	if opts.DomainCacheTTL > 0 {
+		v.EnableDomainCache(time.Duration(opts.DomainCacheTTL))
+	}
+	if opts.ResultCacheTTL > 0 {
+		ttl := ResultCacheTTL{
+			Reachable:   time.Duration(opts.ResultCacheTTL),
+			Unreachable: time.Duration(opts.ResultCacheUnreachableTTL),
+			Unknown:     time.Duration(opts.ResultCacheUnknownTTL),
+		}
+		if ttl.Unreachable == 0 {
+			ttl.Unreachable = ttl.Reachable
+		}
+		if ttl.Unknown == 0 {
+			ttl.Unknown = ttl.Reachable
+		}
+		v.EnableResultCacheTTL(ttl)
+	}
+	if opts.MXLookupAttempts > 1 {
+		v.MXLookupRetry(opts.MXLookupAttempts, time.Duration(opts.MXLookupBackoff))
+	}
+	if opts.ImplicitMX {
+		v.EnableImplicitMX()
+	}
+	if len(opts.FromEmails) > 0 {
+		if err := v.SelectFromEmail(opts.FromEmails); err != nil {
+			return nil, err
+		}
+	}
+	if opts.VerifyFromEmail && !v.VerifyFromEmailDomain() {
+		return nil, fmt.Errorf("from email %s: %w", v.fromEmail, ErrUnroutableFromEmail)
+	}
+	if opts.StrictSyntax {
+		v.EnableStrictSyntax()
+	}
+	if opts.GravatarCheck {
+		v.EnableGravatarCheck()
+	}
+	if opts.GravatarProfile {
+		v.EnableGravatarProfile()
+	}
+	if opts.DMARCCheck {
+		v.EnableDMARCCheck()
+	}
+	if opts.DomainAgeCheck {
+		v.EnableDomainAgeCheck()
+	}
+	if opts.MailServiceCheck {
+		v.EnableMailServicesCheck()
+	}
+
+	v.RejectPolicy(opts.RejectPolicy).AddBlocklist(opts.Blocklist).AddAllowlist(opts.Allowlist)
+	if opts.RoleAccountSMTPSkip {
+		v.EnableRoleAccountSMTPSkip()
+	}
+	v.AddGatewayMXHosts(opts.GatewayMXHosts)
+	if opts.GatewayDowngrade {
+		v.EnableGatewayDowngrade()
+	}
+	if opts.ParkedCheck {
+		v.EnableParkedCheck()
+	}
+	v.AddParkingHosts(opts.ParkingHosts)
+
+	if opts.DomainSuggest {
+		v.EnableDomainSuggest()
+	}
+	if opts.DomainSuggestThreshold > 0 {
+		v.DomainSuggestThreshold(opts.DomainSuggestThreshold)
+	}
+	v.SuggestionLocale(opts.SuggestionLocale).
+		AddSuggestionDomains(opts.SuggestionDomains).
+		AddFreeDomains(opts.FreeDomains).
+		AddRoleAccounts(opts.RoleAccounts)
+
+	if opts.DisposableRepo != nil {
+		v.EnableDisposableCheck(opts.DisposableRepo)
+	} else if opts.AutoUpdateDisposable > 0 {
+		return nil, fmt.Errorf("auto update of disposable domains: %w", ErrDisposableCheckDisabled)
+	}
+	if opts.DisposableReplace {
+		v.EnableDisposableReplace()
+	}
+	if opts.DisposableMaxSize > 0 {
+		v.DisposableMaxSize(opts.DisposableMaxSize)
+	}
+	if opts.DisposableFetchTimeout > 0 {
+		v.DisposableFetchTimeout(time.Duration(opts.DisposableFetchTimeout))
+	}
+	if opts.DisposableFetchAttempts > 1 {
+		v.DisposableFetchRetry(opts.DisposableFetchAttempts, time.Duration(opts.DisposableFetchBackoff))
+	}
+	if opts.AutoUpdateDisposable > 0 {
+		v.EnableAutoUpdateDisposableEvery(time.Duration(opts.AutoUpdateDisposable))
+	}
+	if opts.DisposableSubdomainMatch {
+		v.EnableDisposableSubdomainMatch()
+	}
+	v.AddDisposableMXHosts(opts.DisposableMXHosts)
+
+	return v, nil
+}
+
+// Duration is a time.Duration read from and written to the configuration files
+// in the time.ParseDuration format, e.g. "30s" or "1h30m"
+type Duration time.Duration
+
+// MarshalText formats the duration, e.g. "30s"
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText parses the duration in the time.ParseDuration format
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
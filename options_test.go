@@ -0,0 +1,183 @@
+package emailverifier
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVerifierWithOptions_Defaults(t *testing.T) {
+	v, err := NewVerifierWithOptions(DefaultOptions())
+	assert.NoError(t, err)
+
+	def := NewVerifier()
+	assert.Equal(t, def.smtpCheckEnabled, v.smtpCheckEnabled)
+	assert.Equal(t, def.catchAllCheckEnabled, v.catchAllCheckEnabled)
+	assert.Equal(t, def.heloFallbackEnabled, v.heloFallbackEnabled)
+	assert.Equal(t, def.catchAllPolicy, v.catchAllPolicy)
+	assert.Equal(t, def.fromEmail, v.fromEmail)
+	assert.Equal(t, def.helloName, v.helloName)
+	assert.Equal(t, def.dialNetwork, v.dialNetwork)
+	assert.Equal(t, def.connectTimeout, v.connectTimeout)
+	assert.Equal(t, def.domainSuggestThreshold, v.domainSuggestThreshold)
+	assert.Nil(t, v.mxCache)
+	assert.Nil(t, v.smtpRateLimiter)
+	assert.Nil(t, v.disposableRepo)
+}
+
+func TestNewVerifierWithOptions_FromJSON(t *testing.T) {
+	config := `{
+		"smtp_check": true,
+		"catch_all_check": false,
+		"helo_fallback": false,
+		"vrfy": true,
+		"catch_all_policy": "deliverable",
+		"catch_all_probes": 2,
+		"skip_catch_all": ["example.com"],
+		"from_email": "verify@example.org",
+		"hello_name": "mail.example.org",
+		"sending_ip": "203.0.113.5",
+		"proxy_pool": ["socks5://127.0.0.1:1080"],
+		"user_agent": "verifier/1.0",
+		"http_headers": {"Authorization": ["Bearer token"]},
+		"dial_network": "tcp4",
+		"timeout": "5s",
+		"verify_timeout": "10s",
+		"tarpit_threshold": "20s",
+		"mx_parallelism": 2,
+		"greylist_retry": "2m",
+		"smtp_rate_limit": 2,
+		"smtp_rate_burst": 1,
+		"api_verifiers": ["gmail"],
+		"api_fallback": true,
+		"known_providers": true,
+		"api_retry_attempts": 3,
+		"api_retry_backoff": "1s",
+		"mx_cache_ttl": "1h",
+		"domain_cache_ttl": "30m",
+		"result_cache_ttl": "10m",
+		"result_cache_unreachable_ttl": "24h",
+		"gravatar_check": true,
+		"mail_service_check": true,
+		"domain_suggest": true,
+		"domain_suggest_threshold": 0.9,
+		"suggestion_locale": "de",
+		"free_domains": ["webmail.example"],
+		"role_accounts": ["helpdesk"],
+		"blocklist": ["blocked.example"],
+		"allowlist": ["vip@example.org"],
+		"role_account_smtp_skip": true,
+		"gateway_mx_hosts": ["gateway.example"],
+		"gateway_downgrade": true,
+		"parked_check": true,
+		"parking_hosts": ["parking.example"],
+		"disposable_replace": true,
+		"disposable_fetch_timeout": "30s",
+		"disposable_max_size": 1048576,
+		"disposable_fetch_attempts": 3,
+		"disposable_fetch_backoff": "1m",
+		"disposable_subdomain_match": true,
+		"disposable_mx_hosts": ["mx.throwaway.example."]
+	}`
+	opts := DefaultOptions()
+	assert.NoError(t, json.Unmarshal([]byte(config), &opts))
+	opts.DisposableRepo = newDisposableRepo()
+
+	v, err := NewVerifierWithOptions(opts)
+	assert.NoError(t, err)
+	assert.True(t, v.smtpCheckEnabled)
+	assert.False(t, v.catchAllCheckEnabled)
+	assert.False(t, v.heloFallbackEnabled)
+	assert.True(t, v.vrfyEnabled)
+	assert.Equal(t, CatchAllPolicyDeliverable, v.catchAllPolicy)
+	assert.Equal(t, 2, v.catchAllProbes)
+	assert.True(t, v.skipCatchAll("example.com"))
+	assert.Equal(t, "verify@example.org", v.fromEmail)
+	assert.Equal(t, "mail.example.org", v.helloName)
+	assert.Equal(t, "203.0.113.5", v.sendingIP)
+	assert.NotNil(t, v.proxyPool)
+	assert.Equal(t, "verifier/1.0", v.httpUserAgent)
+	assert.Equal(t, "Bearer token", v.httpHeader.Get("Authorization"))
+	assert.Equal(t, "tcp4", v.dialNetwork)
+	assert.Equal(t, 5*time.Second, v.connectTimeout)
+	assert.Equal(t, 10*time.Second, v.verifyTimeout)
+	assert.Equal(t, 20*time.Second, v.tarpitThreshold)
+	assert.Equal(t, 2, v.mxDialParallelism)
+	assert.True(t, v.greylistRetryEnabled)
+	assert.Equal(t, 2*time.Minute, v.greylistRetryDelay)
+	assert.NotNil(t, v.smtpRateLimiter)
+	assert.Contains(t, v.apiVerifiers, GMAIL)
+	assert.True(t, v.apiFallbackEnabled)
+	assert.True(t, v.knownProvidersEnabled)
+	assert.Equal(t, 3, v.apiRetryAttempts)
+	assert.Equal(t, time.Second, v.apiRetryBackoff)
+	assert.NotNil(t, v.mxCache)
+	assert.Equal(t, 30*time.Minute, v.domainCache.ttl)
+	assert.Equal(t, ResultCacheTTL{Reachable: 10 * time.Minute, Unreachable: 24 * time.Hour, Unknown: 10 * time.Minute}, v.resultCache.ttl)
+	assert.True(t, v.gravatarCheckEnabled)
+	assert.True(t, v.mailServicesCheck)
+	assert.True(t, v.domainSuggestEnabled)
+	assert.Equal(t, float32(0.9), v.domainSuggestThreshold)
+	assert.True(t, v.localeDomains["gmx.de"])
+	assert.True(t, v.IsFreeDomain("webmail.example"))
+	assert.True(t, v.IsRoleAccount("helpdesk"))
+	assert.True(t, v.IsRoleAccount("admin"))
+	assert.NotNil(t, v.disposableRepo)
+	assert.True(t, v.blocklist.has("blocked.example"))
+	assert.True(t, v.allowlist.has("vip@example.org"))
+	assert.True(t, v.roleAccountSMTPSkip)
+	assert.True(t, v.isGatewayMX("mx1.gateway.example."))
+	assert.True(t, v.gatewayDowngrade)
+	assert.True(t, v.parkedCheckEnabled)
+	assert.True(t, v.parkingHosts.hasHost("ns1.parking.example."))
+	assert.True(t, v.disposableReplace)
+	assert.Equal(t, 30*time.Second, v.disposableFetchTimeout)
+	assert.Equal(t, int64(1<<20), v.disposableMaxSize)
+	assert.Equal(t, 3, v.disposableFetchAttempts)
+	assert.Equal(t, time.Minute, v.disposableFetchBackoff)
+	assert.True(t, v.subdomainMatchEnabled)
+	assert.True(t, v.disposableMXHosts.has("mx.throwaway.example"))
+}
+
+func TestNewVerifierWithOptions_Errors(t *testing.T) {
+	opts := DefaultOptions()
+	opts.APIVerifiers = []string{"hotmail"}
+	_, err := NewVerifierWithOptions(opts)
+	assert.Error(t, err)
+
+	opts = DefaultOptions()
+	opts.AutoUpdateDisposable = Duration(time.Hour)
+	_, err = NewVerifierWithOptions(opts)
+	assert.ErrorIs(t, err, ErrDisposableCheckDisabled)
+
+	opts = DefaultOptions()
+	opts.CatchAllPolicy = "optimistic"
+	_, err = NewVerifierWithOptions(opts)
+	assert.Error(t, err)
+
+	// .invalid never resolves (RFC 2606)
+	opts = DefaultOptions()
+	opts.FromEmail = "user@domain.invalid"
+	opts.VerifyFromEmail = true
+	_, err = NewVerifierWithOptions(opts)
+	assert.ErrorIs(t, err, ErrUnroutableFromEmail)
+
+	opts = DefaultOptions()
+	opts.FromEmails = []string{"user@domain.invalid"}
+	_, err = NewVerifierWithOptions(opts)
+	assert.ErrorIs(t, err, ErrUnroutableFromEmail)
+}
+
+func TestDuration_JSON(t *testing.T) {
+	var d Duration
+	assert.NoError(t, json.Unmarshal([]byte(`"1h30m"`), &d))
+	assert.Equal(t, Duration(90*time.Minute), d)
+
+	data, err := json.Marshal(d)
+	assert.NoError(t, err)
+	assert.Equal(t, `"1h30m0s"`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`"soon"`), &d))
+}
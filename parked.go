@@ -0,0 +1,85 @@
+package emailverifier
+
+import "strings"
+
+// EnableParkedCheck detects the parked domains in Verify, i.e. the domains whose MX hosts or name servers
+// belong to a domain parking provider, see Result.Parked. It costs an NS lookup per domain.
+func (v *Verifier) EnableParkedCheck() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.parkedCheckEnabled = true
+	return v
+}
+
+// DisableParkedCheck disables the detection of the parked domains (default)
+func (v *Verifier) DisableParkedCheck() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.parkedCheckEnabled = false
+	return v
+}
+
+// AddParkingHosts marks the name servers or MX hosts, and all the hosts under them, as the ones
+// of a domain parking provider in addition to the built-in ones, e.g. "sedoparking.com"
+func (v *Verifier) AddParkingHosts(hosts []string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	normalized := make([]string, len(hosts))
+	for i, h := range hosts {
+		normalized[i] = strings.TrimSuffix(h, ".")
+	}
+	v.parkingHosts.add(normalized)
+	return v
+}
+
+// IsParkedDomain checks if the domain is parked, i.e. its MX hosts or name servers belong to
+// a domain parking provider. Such domains accept mail that goes nowhere.
+func (v *Verifier) IsParkedDomain(domain string) (bool, error) {
+	v = v.snapshot()
+	domain = DomainToASCII(domain)
+
+	records, _, err := v.resolveMX(domain)
+	if err != nil && !isNotFoundDNSError(err) {
+		return false, err
+	}
+	hosts := make([]string, len(records))
+	for i, r := range records {
+		hosts[i] = r.Host
+	}
+	return v.isParked(domain, hosts)
+}
+
+// isParked checks the MX hosts of the domain, and its name servers, against the parking providers
+func (v *Verifier) isParked(domain string, mxHosts []string) (bool, error) {
+	if parked, ok := v.domainCache.parked(domain); ok {
+		return parked, nil
+	}
+	parked, err := v.lookupParked(domain, mxHosts)
+	if err == nil {
+		v.domainCache.setParked(domain, parked)
+	}
+	return parked, err
+}
+
+// lookupParked looks up the name servers of the domain unless its MX hosts are the parking ones
+func (v *Verifier) lookupParked(domain string, mxHosts []string) (bool, error) {
+	for _, host := range mxHosts {
+		if v.parkingHosts.hasHost(host) {
+			return true, nil
+		}
+	}
+
+	ns, err := v.lookupNS(domain)
+	if err != nil && !isNotFoundDNSError(err) {
+		return false, err
+	}
+	for _, n := range ns {
+		if v.parkingHosts.hasHost(n.Host) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,79 @@
+package emailverifier
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parkedResolver() *mockResolver {
+	return &mockResolver{
+		mx: map[string][]*net.MX{
+			"parked-mx.test": {{Host: "mx76.m1bp.com.", Pref: 10}, {Host: "mail.parkingcrew.net.", Pref: 20}},
+			"parked-ns.test": {{Host: "mx.parked-ns.test.", Pref: 10}},
+			"custom.test":    {{Host: "mx.custom.test.", Pref: 10}},
+			"example.test":   {{Host: "mx.example.test.", Pref: 10}},
+		},
+		ns: map[string][]*net.NS{
+			"parked-ns.test": {{Host: "NS1.SEDOPARKING.COM."}, {Host: "ns2.sedoparking.com."}},
+			"custom.test":    {{Host: "ns1.parking.example."}},
+			"example.test":   {{Host: "ns1.example.test."}},
+		},
+	}
+}
+
+func TestIsParkedDomain(t *testing.T) {
+	v := NewVerifier().EnableMXResolver(parkedResolver())
+
+	for domain, expected := range map[string]bool{
+		"parked-mx.test": true,
+		"parked-ns.test": true,
+		"custom.test":    false,
+		"example.test":   false,
+		"missing.test":   false,
+	} {
+		parked, err := v.IsParkedDomain(domain)
+		assert.NoError(t, err, domain)
+		assert.Equal(t, expected, parked, domain)
+	}
+
+	parked, err := v.AddParkingHosts([]string{"parking.example."}).IsParkedDomain("custom.test")
+	assert.NoError(t, err)
+	assert.True(t, parked)
+}
+
+func TestVerify_Parked(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	verifier := NewVerifier().
+		EnableSMTPCheck().
+		EnableCustomDialer(srv.dialer()).
+		EnableMXResolver(parkedResolver())
+
+	// Parked domains accept everything
+	ret, err := verifier.Verify("john@parked-ns.test")
+	assert.NoError(t, err)
+	assert.False(t, ret.Parked)
+	assert.Equal(t, ReasonCatchAll, ret.Reason)
+
+	// The parked domain is flagged, but probed as usual
+	ret, err = verifier.EnableParkedCheck().Verify("john@parked-ns.test")
+	assert.NoError(t, err)
+	assert.True(t, ret.Parked)
+	assert.NotNil(t, ret.SMTP)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Equal(t, ReasonParkedDomain, ret.Reason)
+
+	// The policy rejects it without probing
+	ret, err = verifier.RejectPolicy(RejectPolicy{Parked: true}).Verify("john@parked-ns.test")
+	assert.NoError(t, err)
+	assert.True(t, ret.Parked)
+	assert.Nil(t, ret.SMTP)
+	assert.Equal(t, reachableNo, ret.Reachable)
+	assert.Equal(t, ReasonParkedDomain, ret.Reason)
+
+	ret, err = verifier.Verify("john@example.test")
+	assert.NoError(t, err)
+	assert.False(t, ret.Parked)
+	assert.NotNil(t, ret.SMTP)
+}
@@ -0,0 +1,159 @@
+package emailverifier
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CatchAllPolicy decides the reachability of the addresses at a catch-all domain,
+// which accepts any address so the user can not be confirmed
+type CatchAllPolicy string
+
+const (
+	CatchAllPolicyUnknown       CatchAllPolicy = "unknown"       // reachable "unknown" (the default)
+	CatchAllPolicyDeliverable   CatchAllPolicy = "deliverable"   // reachable "yes", i.e. optimistic
+	CatchAllPolicyUndeliverable CatchAllPolicy = "undeliverable" // reachable "no", i.e. pessimistic
+)
+
+// RejectPolicy lists the kinds of addresses rejected by Verify without the network checks,
+// i.e. with Reachable "no" and the matching reason in Result.Reason: ReasonDisposable,
+// ReasonRoleAccount, ReasonFreeDomain or ReasonParkedDomain. The addresses at the parked domains,
+// detected by EnableParkedCheck, are rejected after the MX check, but before the SMTP check.
+type RejectPolicy struct {
+	Disposable  bool `json:"disposable" yaml:"disposable"`
+	RoleAccount bool `json:"role_account" yaml:"role_account"`
+	Free        bool `json:"free" yaml:"free"`
+	Parked      bool `json:"parked" yaml:"parked"`
+}
+
+// requiredMailboxes must exist at every domain accepting mail: postmaster by RFC 5321 and abuse by RFC 2142
+var requiredMailboxes = map[string]bool{
+	"postmaster": true,
+	"abuse":      true,
+}
+
+// isRequiredMailbox checks if the username is a mailbox the RFCs require at every domain accepting mail
+func isRequiredMailbox(username string) bool {
+	return requiredMailboxes[strings.ToLower(username)]
+}
+
+// EnableRoleAccountSMTPSkip skips the SMTP check of role accounts, e.g. to avoid probing the monitored admin@,
+// they are reported with reachable "unknown" and ReasonRoleAccount
+func (v *Verifier) EnableRoleAccountSMTPSkip() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.roleAccountSMTPSkip = true
+	return v
+}
+
+// DisableRoleAccountSMTPSkip checks role accounts via SMTP like the other addresses (default)
+func (v *Verifier) DisableRoleAccountSMTPSkip() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.roleAccountSMTPSkip = false
+	return v
+}
+
+// RejectPolicy sets the kinds of addresses rejected by Verify before the MX and SMTP checks,
+// the zero policy rejects nothing (the default)
+func (v *Verifier) RejectPolicy(policy RejectPolicy) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.rejectPolicy = policy
+	return v
+}
+
+// rejectReason returns the reason of rejecting the address by the policy, empty when not rejected
+func (p RejectPolicy) rejectReason(ret *Result) string {
+	switch {
+	case p.Disposable && ret.Disposable:
+		return ReasonDisposable
+	case p.RoleAccount && ret.RoleAccount:
+		return ReasonRoleAccount
+	case p.Free && ret.Free:
+		return ReasonFreeDomain
+	case p.Parked && ret.Parked:
+		return ReasonParkedDomain
+	default:
+		return ""
+	}
+}
+
+// CatchAllPolicy sets the reachability of the addresses at a catch-all domain,
+// an empty policy restores the default CatchAllPolicyUnknown
+func (v *Verifier) CatchAllPolicy(policy CatchAllPolicy) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if policy == "" {
+		policy = CatchAllPolicyUnknown
+	}
+	v.catchAllPolicy = policy
+	return v
+}
+
+// validate checks the policy is one of the known ones
+func (p CatchAllPolicy) validate() error {
+	switch p {
+	case "", CatchAllPolicyUnknown, CatchAllPolicyDeliverable, CatchAllPolicyUndeliverable:
+		return nil
+	default:
+		return fmt.Errorf("unsupported catch-all policy: %q", string(p))
+	}
+}
+
+// reachable returns the reachability of an address at a catch-all domain
+func (p CatchAllPolicy) reachable() string {
+	switch p {
+	case CatchAllPolicyDeliverable:
+		return reachableYes
+	case CatchAllPolicyUndeliverable:
+		return reachableNo
+	default:
+		return reachableUnknown
+	}
+}
+
+// AddBlocklist adds the addresses (e.g. "user@domain.org") and the domains (e.g. "domain.org") rejected by Verify
+// before any other check, i.e. with Reachable "no" and ReasonBlocklisted. The blocklist wins over the allowlist.
+func (v *Verifier) AddBlocklist(entries []string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.blocklist.add(listEntries(entries))
+	return v
+}
+
+// AddAllowlist adds the addresses (e.g. "user@domain.org") and the domains (e.g. "domain.org") accepted by Verify
+// without the MX and SMTP checks, i.e. with Reachable "yes" and ReasonAllowlisted
+func (v *Verifier) AddAllowlist(entries []string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.allowlist.add(listEntries(entries))
+	return v
+}
+
+// listEntries normalizes the domains of the allowlist or blocklist entries to ASCII,
+// so the entries match the addresses at an IDN in either form
+func listEntries(entries []string) []string {
+	ret := make([]string, len(entries))
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if at := strings.LastIndex(entry, "@"); at >= 0 {
+			ret[i] = entry[:at+1] + DomainToASCII(entry[at+1:])
+		} else {
+			ret[i] = DomainToASCII(entry)
+		}
+	}
+	return ret
+}
+
+// listed checks if the address or its domain is in the allowlist or blocklist
+func listed(list *stringSet, syntax Syntax) bool {
+	domain := DomainToASCII(syntax.Domain)
+	return list.has(domain) || list.has(syntax.Username+"@"+domain)
+}
@@ -0,0 +1,155 @@
+package emailverifier
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify_RejectPolicy(t *testing.T) {
+	dr := newDisposableRepo()
+	dr.AddDisposableDomains([]string{"disposable.test"})
+	srv := newMockSMTPServer(t)
+	v := srv.verifier("gmail.com", "example.test").
+		EnableDisposableCheck(dr).
+		RejectPolicy(RejectPolicy{Disposable: true, RoleAccount: true, Free: true})
+
+	cases := map[string]string{
+		"someone@disposable.test": ReasonDisposable,
+		"admin@gmail.com":         ReasonRoleAccount,
+		"someone@gmail.com":       ReasonFreeDomain,
+	}
+	for email, reason := range cases {
+		ret, err := v.Verify(email)
+		assert.NoError(t, err, email)
+		assert.Equal(t, reason, ret.Reason, email)
+		assert.Equal(t, reachableNo, ret.Reachable, email)
+		assert.Nil(t, ret.SMTP, email)
+	}
+	assert.Empty(t, srv.received())
+
+	ret, err := v.Verify("someone@example.test")
+	assert.NoError(t, err)
+	assert.NotNil(t, ret.SMTP)
+	assert.Equal(t, ReasonCatchAll, ret.Reason)
+}
+
+func TestVerify_Blocklist(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	v := srv.verifier("example.test", "example.org").
+		AddBlocklist([]string{"Blocked.Example.Test", "someone@example.org"}).
+		AddAllowlist([]string{"example.org", "someone@blocked.example.test"})
+
+	for _, email := range []string{"anyone@blocked.example.test", "someone@blocked.example.test", "SOMEONE@Example.org"} {
+		ret, err := v.Verify(email)
+		assert.NoError(t, err, email)
+		assert.Equal(t, reachableNo, ret.Reachable, email)
+		assert.Equal(t, ReasonBlocklisted, ret.Reason, email)
+	}
+	assert.Empty(t, srv.received())
+}
+
+func TestVerify_Allowlist(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	v := srv.verifier("example.test", "münchen.de").
+		RejectPolicy(RejectPolicy{RoleAccount: true}).
+		AddAllowlist([]string{"admin@example.test", "xn--mnchen-3ya.de"})
+
+	for _, email := range []string{"admin@example.test", "someone@münchen.de"} {
+		ret, err := v.Verify(email)
+		assert.NoError(t, err, email)
+		assert.Equal(t, reachableYes, ret.Reachable, email)
+		assert.Equal(t, ReasonAllowlisted, ret.Reason, email)
+		assert.Nil(t, ret.SMTP, email)
+	}
+	assert.Empty(t, srv.received())
+
+	ret, err := v.Verify("someone@example.test")
+	assert.NoError(t, err)
+	assert.NotNil(t, ret.SMTP)
+}
+
+func TestVerify_RejectPolicyDisposableByMX(t *testing.T) {
+	v := NewVerifier().
+		EnableDisposableCheck(newDisposableRepo()).
+		EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+			"throwaway.example": {{Host: "mx.disposable.example.", Pref: 10}},
+		}}).
+		AddDisposableMXHosts([]string{"mx.disposable.example."}).
+		RejectPolicy(RejectPolicy{Disposable: true})
+
+	ret, err := v.Verify("someone@throwaway.example")
+	assert.NoError(t, err)
+	assert.True(t, ret.Disposable)
+	assert.Equal(t, reachableNo, ret.Reachable)
+	assert.Equal(t, ReasonDisposable, ret.Reason)
+}
+
+func TestVerify_RejectPolicyDisabledByDefault(t *testing.T) {
+	dr := newDisposableRepo()
+	dr.AddDisposableDomains([]string{"disposable.test"})
+
+	ret, err := NewVerifier().EnableDisposableCheck(dr).Verify("admin@disposable.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Equal(t, ReasonDisposable, ret.Reason)
+}
+
+func TestCalculateReachable_CatchAllPolicy(t *testing.T) {
+	catchAll := &SMTP{HostExists: true, CatchAll: true, CatchAllStatus: CatchAllYes}
+	cases := map[CatchAllPolicy]string{
+		"":                          reachableUnknown,
+		CatchAllPolicyUnknown:       reachableUnknown,
+		CatchAllPolicyDeliverable:   reachableYes,
+		CatchAllPolicyUndeliverable: reachableNo,
+	}
+	for policy, reachable := range cases {
+		v := NewVerifier().EnableSMTPCheck().CatchAllPolicy(policy)
+		assert.Equal(t, reachable, v.calculateReachable(catchAll), string(policy))
+		// the policy does not affect the addresses at the other domains
+		assert.Equal(t, reachableNo, v.calculateReachable(&SMTP{HostExists: true, CatchAllStatus: CatchAllNo}), string(policy))
+	}
+}
+
+func TestVerify_CatchAllPolicyDeliverable(t *testing.T) {
+	srv := newMockSMTPServer(t)
+
+	ret, err := srv.verifier("example.test").CatchAllPolicy(CatchAllPolicyDeliverable).Verify("someone@example.test")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.CatchAll)
+	assert.Equal(t, reachableYes, ret.Reachable)
+	assert.Empty(t, ret.Reason)
+}
+
+func TestVerify_RequiredMailboxNotProbed(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	v := srv.verifier("example.test")
+
+	for _, email := range []string{"postmaster@example.test", "Abuse@example.test", "postmaster+tag@example.test"} {
+		ret, err := v.Verify(email)
+		assert.NoError(t, err, email)
+		assert.Equal(t, reachableYes, ret.Reachable, email)
+		assert.Nil(t, ret.SMTP, email)
+		assert.Empty(t, ret.Reason, email)
+		assert.True(t, ret.Score >= 90, email)
+	}
+	assert.Empty(t, srv.received())
+}
+
+func TestVerify_RoleAccountSMTPSkip(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	v := srv.verifier("example.test").EnableRoleAccountSMTPSkip()
+
+	ret, err := v.Verify("admin@example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Equal(t, ReasonRoleAccount, ret.Reason)
+	assert.Nil(t, ret.SMTP)
+	assert.Empty(t, srv.received())
+
+	ret, err = v.DisableRoleAccountSMTPSkip().Verify("admin@example.test")
+	assert.NoError(t, err)
+	assert.NotNil(t, ret.SMTP)
+	assert.NotEmpty(t, filterCommands(srv.received(), "RCPT"))
+}
@@ -0,0 +1,63 @@
+package emailverifier
+
+import (
+	"sync"
+	"time"
+)
+
+// proxyPool is a goroutine-safe round-robin pool of proxy URIs, proxies failing
+// to connect are skipped until their cooldown expires
+type proxyPool struct {
+	mu       sync.Mutex
+	uris     []string
+	next     int
+	cooldown time.Duration        // how long a failing proxy is skipped
+	failed   map[string]time.Time // proxy URI -> time until which it is skipped
+}
+
+// newProxyPool creates a new pool of the proxy URIs
+func newProxyPool(uris []string, cooldown time.Duration) *proxyPool {
+	return &proxyPool{
+		uris:     append([]string(nil), uris...),
+		cooldown: cooldown,
+		failed:   map[string]time.Time{},
+	}
+}
+
+// pick returns the next usable proxy URI. When every proxy is cooling down,
+// the one recovering soonest is returned rather than failing the connection
+func (p *proxyPool) pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	soonest := -1
+	for i := 0; i < len(p.uris); i++ {
+		idx := (p.next + i) % len(p.uris)
+		until, ok := p.failed[p.uris[idx]]
+		if !ok || now.After(until) {
+			p.next = idx + 1
+			return p.uris[idx]
+		}
+		if soonest < 0 || until.Before(p.failed[p.uris[soonest]]) {
+			soonest = idx
+		}
+	}
+
+	p.next = soonest + 1
+	return p.uris[soonest]
+}
+
+// markFailed skips the proxy for the cooldown period
+func (p *proxyPool) markFailed(uri string) {
+	p.mu.Lock()
+	p.failed[uri] = time.Now().Add(p.cooldown)
+	p.mu.Unlock()
+}
+
+// markOK makes the proxy usable again
+func (p *proxyPool) markOK(uri string) {
+	p.mu.Lock()
+	delete(p.failed, uri)
+	p.mu.Unlock()
+}
@@ -0,0 +1,69 @@
+package emailverifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyPoolPick_RoundRobin(t *testing.T) {
+	p := newProxyPool([]string{"socks5://a:1080", "socks5://b:1080"}, time.Minute)
+
+	assert.Equal(t, "socks5://a:1080", p.pick())
+	assert.Equal(t, "socks5://b:1080", p.pick())
+	assert.Equal(t, "socks5://a:1080", p.pick())
+}
+
+func TestProxyPoolPick_SkipFailed(t *testing.T) {
+	p := newProxyPool([]string{"socks5://a:1080", "socks5://b:1080"}, time.Minute)
+	p.markFailed("socks5://a:1080")
+
+	assert.Equal(t, "socks5://b:1080", p.pick())
+	assert.Equal(t, "socks5://b:1080", p.pick())
+
+	p.markOK("socks5://a:1080")
+	assert.Equal(t, "socks5://a:1080", p.pick())
+}
+
+func TestProxyPoolPick_AllFailed(t *testing.T) {
+	p := newProxyPool([]string{"socks5://a:1080", "socks5://b:1080"}, time.Minute)
+	p.markFailed("socks5://b:1080")
+	time.Sleep(time.Millisecond)
+	p.markFailed("socks5://a:1080")
+
+	assert.Equal(t, "socks5://b:1080", p.pick())
+}
+
+func TestProxyPoolPick_CooldownExpired(t *testing.T) {
+	p := newProxyPool([]string{"socks5://a:1080", "socks5://b:1080"}, -time.Second)
+	p.markFailed("socks5://a:1080")
+
+	assert.Equal(t, "socks5://a:1080", p.pick())
+}
+
+func TestCheckSMTPForMXOK_ProxyPoolSkipsDeadProxy(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@example.com")
+	ln, _ := newMockHTTPProxy(t, srv.ln.Addr().String(), "dXNlcjpwYXNzd29yZA==")
+
+	// nothing listens on the first proxy
+	dead := newMockSMTPServer(t)
+	deadAddr := dead.ln.Addr().String()
+	_ = dead.ln.Close()
+
+	verifier := NewVerifier().EnableSMTPCheck().ProxyPool([]string{
+		"http://" + deadAddr,
+		"http://user:password@" + ln.Addr().String(),
+	})
+	_, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.Error(t, err)
+
+	// the dead proxy is cooling down, so the healthy one is picked again
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.Deliverable)
+	smtp, err = verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.Deliverable)
+}
@@ -0,0 +1,78 @@
+package emailverifier
+
+import (
+	"crypto/rand"
+	"fmt"
+	"unicode/utf8"
+)
+
+const defaultRandomEmailLength = 32
+
+// RandomEmailGenerator generates a random email address using the domain passed
+type RandomEmailGenerator func(domain string) string
+
+// GenerateRandomEmail generates a random email address using the domain passed. Used
+// primarily for checking the existence of a catch-all address
+func GenerateRandomEmail(domain string) string {
+	return fmt.Sprintf("%s@%s", randomString(defaultRandomEmailLength, alphanumeric), domain)
+}
+
+// NewRandomEmailGenerator creates a generator of random email addresses whose
+// username has the given length and consists of the characters of the charset.
+// Non-positive length and empty or non-ASCII charset fall back to the defaults of GenerateRandomEmail,
+// the repeated characters of the charset are picked as often as the others.
+func NewRandomEmailGenerator(length int, charset string) RandomEmailGenerator {
+	if length <= 0 {
+		length = defaultRandomEmailLength
+	}
+	if charset = distinctASCII(charset); charset == "" {
+		charset = alphanumeric
+	}
+	return func(domain string) string {
+		return fmt.Sprintf("%s@%s", randomString(length, charset), domain)
+	}
+}
+
+// distinctASCII returns the distinct characters of the charset in their order,
+// or an empty string when the charset is not ASCII
+func distinctASCII(charset string) string {
+	var seen [utf8.RuneSelf]bool
+	r := make([]byte, 0, len(charset))
+	for i := 0; i < len(charset); i++ {
+		c := charset[i]
+		if c >= utf8.RuneSelf {
+			return ""
+		}
+		if !seen[c] {
+			seen[c] = true
+			r = append(r, c)
+		}
+	}
+	return string(r)
+}
+
+// randomString returns a string of the given length consisting of characters picked
+// uniformly at random from the charset using crypto/rand, the charset consists of
+// at most 256 single-byte characters
+func randomString(length int, charset string) string {
+	// largest multiple of the charset length fitting into a byte, used to avoid modulo bias
+	limit := 256 - 256%len(charset)
+
+	r := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(r) < length {
+		if _, err := rand.Read(buf); err != nil {
+			panic(err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			r = append(r, charset[int(b)%len(charset)])
+			if len(r) == length {
+				break
+			}
+		}
+	}
+	return string(r)
+}
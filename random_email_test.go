@@ -0,0 +1,73 @@
+package emailverifier
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateRandomEmail(t *testing.T) {
+	email := GenerateRandomEmail("example.com")
+
+	parts := strings.SplitN(email, "@", 2)
+	username, domain := parts[0], parts[1]
+	assert.Equal(t, "example.com", domain)
+	assert.Len(t, username, 32)
+	for _, c := range username {
+		assert.Contains(t, alphanumeric, string(c))
+	}
+	assert.NotEqual(t, email, GenerateRandomEmail("example.com"))
+}
+
+func TestNewRandomEmailGenerator(t *testing.T) {
+	gen := NewRandomEmailGenerator(12, "ab")
+	parts := strings.SplitN(gen("example.com"), "@", 2)
+	username, domain := parts[0], parts[1]
+
+	assert.Equal(t, "example.com", domain)
+	assert.Len(t, username, 12)
+	assert.Empty(t, strings.Trim(username, "ab"))
+}
+
+func TestNewRandomEmailGenerator_Defaults(t *testing.T) {
+	username := strings.SplitN(NewRandomEmailGenerator(0, "")("example.com"), "@", 2)[0]
+
+	assert.Len(t, username, 32)
+	for _, c := range username {
+		assert.Contains(t, alphanumeric, string(c))
+	}
+}
+
+func TestNewRandomEmailGenerator_InvalidCharset(t *testing.T) {
+	// The charset longer than 256 characters is reduced to the distinct ones
+	username := strings.SplitN(NewRandomEmailGenerator(16, strings.Repeat("ab", 200))("example.com"), "@", 2)[0]
+	assert.Len(t, username, 16)
+	assert.Empty(t, strings.Trim(username, "ab"))
+
+	// The non-ASCII charset falls back to the default one
+	username = strings.SplitN(NewRandomEmailGenerator(16, "äöü")("example.com"), "@", 2)[0]
+	assert.Len(t, username, 16)
+	assert.True(t, utf8.ValidString(username))
+	for _, c := range username {
+		assert.Contains(t, alphanumeric, string(c))
+	}
+}
+
+func TestDistinctASCII(t *testing.T) {
+	assert.Equal(t, "ab", distinctASCII("abba"))
+	assert.Equal(t, "", distinctASCII("aä"))
+}
+
+func TestCheckSMTPForMXOK_CustomRandomEmailGenerator(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@example.test")
+
+	verifier := NewVerifier().EnableSMTPCheck().
+		EnableCustomDialer(srv.dialer()).
+		RandomEmailGenerator(func(domain string) string { return "jane.doe@" + domain })
+	_, err := verifier.CheckSMTPForMX([]string{"mx.example.test."}, "example.test", "someone")
+	assert.NoError(t, err)
+	assert.Contains(t, srv.received(), "RCPT TO:<jane.doe@example.test>")
+}
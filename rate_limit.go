@@ -0,0 +1,49 @@
+package emailverifier
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// hostRateLimiter is a goroutine-safe limiter of the rate of the SMTP connections per MX host
+type hostRateLimiter struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*rate.Limiter
+}
+
+// newHostRateLimiter creates a new limiter allowing limit connections per second
+// to every MX host, with bursts of at most burst connections
+func newHostRateLimiter(limit rate.Limit, burst int) *hostRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &hostRateLimiter{
+		limit:    limit,
+		burst:    burst,
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+// wait blocks until a connection to the host is allowed or the context is done
+func (l *hostRateLimiter) wait(ctx context.Context, host string) error {
+	return l.limiter(host).Wait(ctx)
+}
+
+// limiter returns the limiter of the host, creating it when missing
+func (l *hostRateLimiter) limiter(host string) *rate.Limiter {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lim, ok := l.limiters[host]
+	if !ok {
+		lim = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[host] = lim
+	}
+	return lim
+}
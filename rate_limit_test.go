@@ -0,0 +1,50 @@
+package emailverifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestHostRateLimiter_PerHost(t *testing.T) {
+	l := newHostRateLimiter(rate.Every(time.Hour), 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, l.wait(ctx, "mx1.example.com."))
+	assert.NoError(t, l.wait(ctx, "mx2.example.com."))
+	// the same host, regardless of the case and the trailing dot, has to wait
+	assert.Error(t, l.wait(ctx, "MX1.example.com"))
+}
+
+func TestHostRateLimiter_Burst(t *testing.T) {
+	l := newHostRateLimiter(rate.Every(time.Hour), 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, l.wait(ctx, "mx.example.com."))
+	assert.NoError(t, l.wait(ctx, "mx.example.com."))
+	assert.Error(t, l.wait(ctx, "mx.example.com."))
+}
+
+func TestCheckSMTPForMXOK_RateLimited(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@example.com")
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer()).SMTPRateLimit(10, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+		assert.NoError(t, err)
+		assert.True(t, smtp.Deliverable)
+	}
+	assert.True(t, time.Since(start) >= 150*time.Millisecond)
+}
+
+func TestSMTPRateLimit_Disabled(t *testing.T) {
+	assert.NotNil(t, NewVerifier().SMTPRateLimit(1, 1).smtpRateLimiter)
+	assert.Nil(t, NewVerifier().SMTPRateLimit(1, 1).SMTPRateLimit(0, 1).smtpRateLimiter)
+}
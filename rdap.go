@@ -0,0 +1,291 @@
+package emailverifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRDAPNotSupported is returned when the registry of the domain offers no RDAP service
+	ErrRDAPNotSupported = errors.New("RDAP is not supported for the domain")
+	// ErrRDAPNotFound is returned when the RDAP server knows no such domain
+	ErrRDAPNotFound = errors.New("RDAP domain not found")
+)
+
+// RDAP is the registration data of a domain
+type RDAP struct {
+	Domain      string    `json:"domain"`       // the domain in its ASCII form
+	Registrar   string    `json:"registrar"`    // name of the sponsoring registrar
+	Registered  time.Time `json:"registered"`   // registration date
+	Expires     time.Time `json:"expires"`      // expiration date
+	LastChanged time.Time `json:"last_changed"` // date of the last change
+	// Status holds the status flags of RFC 8056, e.g. "active", "client hold" or "pending delete"
+	Status []string `json:"status"`
+}
+
+// HasStatus checks if the domain has the status flag, ignoring the case and the separators,
+// i.e. both "clientHold" and "client hold" match
+func (r *RDAP) HasStatus(status string) bool {
+	key := rdapStatusKey(status)
+	for _, s := range r.Status {
+		if rdapStatusKey(s) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func rdapStatusKey(status string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(status))
+}
+
+// CheckRDAP looks up the registration data of the domain at the RDAP server of its registry,
+// found via the IANA bootstrap registry. ErrRDAPNotSupported is returned for the domains
+// without an RDAP service, ErrRDAPNotFound for the domains unknown to the registry.
+func (v *Verifier) CheckRDAP(domain string) (*RDAP, error) {
+	ctx, cancel := context.WithTimeout(v.context(), 10*time.Second)
+	defer cancel()
+
+	domain = strings.ToLower(DomainToASCII(domain))
+	d, err := v.queryRDAP(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := RDAP{
+		Domain:    domain,
+		Registrar: d.registrar(),
+		Status:    d.Status,
+	}
+	ret.Registered, _ = d.event("registration")
+	ret.Expires, _ = d.event("expiration")
+	ret.LastChanged, _ = d.event("last changed")
+	return &ret, nil
+}
+
+// rdapDomain is the part of the RDAP domain object (RFC 9083) used by the checks
+type rdapDomain struct {
+	Events   []rdapEvent  `json:"events"`
+	Status   []string     `json:"status"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+type rdapEvent struct {
+	Action string    `json:"eventAction"`
+	Date   time.Time `json:"eventDate"`
+}
+
+type rdapEntity struct {
+	Roles []string `json:"roles"`
+	// VCard is the jCard (RFC 7095) of the entity: ["vcard", [[name, params, type, value], ...]]
+	VCard []interface{} `json:"vcardArray"`
+}
+
+// event returns the date of the first event with the action, e.g. "registration"
+func (d *rdapDomain) event(action string) (time.Time, bool) {
+	for _, e := range d.Events {
+		if e.Action == action {
+			return e.Date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// registrar returns the formatted name of the registrar entity
+func (d *rdapDomain) registrar() string {
+	for _, e := range d.Entities {
+		for _, role := range e.Roles {
+			if role == "registrar" {
+				return e.formattedName()
+			}
+		}
+	}
+	return ""
+}
+
+// formattedName returns the "fn" property of the jCard
+func (e *rdapEntity) formattedName() string {
+	if len(e.VCard) != 2 {
+		return ""
+	}
+	props, _ := e.VCard[1].([]interface{})
+	for _, p := range props {
+		prop, _ := p.([]interface{})
+		if len(prop) == 4 && prop[0] == "fn" {
+			name, _ := prop[3].(string)
+			return name
+		}
+	}
+	return ""
+}
+
+// queryRDAP fetches the RDAP domain object of the ASCII domain
+func (v *Verifier) queryRDAP(ctx context.Context, domain string) (*rdapDomain, error) {
+	client := v.httpClient(http.DefaultClient)
+	server, err := v.rdapServers.lookup(ctx, client, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret rdapDomain
+	err = getJSON(ctx, client, server+"domain/"+domain, "application/rdap+json", &ret)
+	if err == errHTTPNotFound {
+		return nil, ErrRDAPNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query RDAP of %s: %w", domain, err)
+	}
+	return &ret, nil
+}
+
+// rdapBootstrap is a goroutine-safe cache of the IANA bootstrap registry of the RDAP servers (RFC 9224)
+type rdapBootstrap struct {
+	mu       sync.Mutex
+	url      string
+	ttl      time.Duration     // how long the fetched registry is used
+	servers  map[string]string // TLD -> base URL of its RDAP server, ending with a slash
+	expires  time.Time
+	fetching chan struct{} // closed once the fetch in flight is done, nil when none
+}
+
+func newRDAPBootstrap(url string, ttl time.Duration) *rdapBootstrap {
+	return &rdapBootstrap{url: url, ttl: ttl}
+}
+
+// lookup returns the base URL of the RDAP server of the ASCII domain,
+// the registry is fetched on the first use and refreshed after its ttl
+func (b *rdapBootstrap) lookup(ctx context.Context, client *http.Client, domain string) (string, error) {
+	servers, err := b.registry(ctx, client)
+	if err != nil {
+		return "", err
+	}
+
+	// The longest matching label suffix wins
+	for suffix := domain; ; {
+		if server, ok := servers[suffix]; ok {
+			return server, nil
+		}
+		i := strings.IndexByte(suffix, '.')
+		if i < 0 {
+			return "", ErrRDAPNotSupported
+		}
+		suffix = suffix[i+1:]
+	}
+}
+
+// registry returns the fetched registry, fetching it on the first use and after its ttl. The registry is
+// fetched by one caller at a time without holding the lock, the others use the expired registry meanwhile,
+// or wait for the fetch when there is none yet.
+func (b *rdapBootstrap) registry(ctx context.Context, client *http.Client) (map[string]string, error) {
+	for {
+		b.mu.Lock()
+		servers, fetching := b.servers, b.fetching
+		switch {
+		case servers != nil && !time.Now().After(b.expires):
+			b.mu.Unlock()
+			return servers, nil
+		case fetching == nil:
+			done := make(chan struct{})
+			b.fetching = done
+			b.mu.Unlock()
+			return b.fetch(ctx, client, done)
+		}
+		b.mu.Unlock()
+
+		if servers != nil {
+			return servers, nil
+		}
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// fetch fetches the registry and swaps it in, done is closed once it is over
+func (b *rdapBootstrap) fetch(ctx context.Context, client *http.Client, done chan struct{}) (map[string]string, error) {
+	servers, err := fetchRDAPBootstrap(ctx, client, b.url)
+
+	b.mu.Lock()
+	if err == nil {
+		b.servers = servers
+		b.expires = time.Now().Add(b.ttl)
+	}
+	b.fetching = nil
+	b.mu.Unlock()
+	close(done)
+	return servers, err
+}
+
+// fetchRDAPBootstrap fetches the bootstrap registry and maps the TLDs to the RDAP servers
+func fetchRDAPBootstrap(ctx context.Context, client *http.Client, url string) (map[string]string, error) {
+	var registry struct {
+		Services [][][]string `json:"services"`
+	}
+	if err := getJSON(ctx, client, url, "application/json", &registry); err != nil {
+		return nil, fmt.Errorf("fetch RDAP bootstrap: %w", err)
+	}
+
+	servers := map[string]string{}
+	for _, service := range registry.Services {
+		if len(service) != 2 || len(service[1]) == 0 {
+			continue
+		}
+		server := pickRDAPServer(service[1])
+		for _, tld := range service[0] {
+			servers[strings.ToLower(tld)] = server
+		}
+	}
+	return servers, nil
+}
+
+// pickRDAPServer prefers the HTTPS server of the service
+func pickRDAPServer(urls []string) string {
+	server := urls[0]
+	for _, u := range urls {
+		if strings.HasPrefix(u, "https://") {
+			server = u
+			break
+		}
+	}
+	if !strings.HasSuffix(server, "/") {
+		server += "/"
+	}
+	return server
+}
+
+// errHTTPNotFound is returned by getJSON when the server responds 404
+var errHTTPNotFound = errors.New("not found")
+
+// getJSON fetches the url and decodes the JSON response into out
+func getJSON(ctx context.Context, client *http.Client, url, accept string, out interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errHTTPNotFound
+	default:
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,118 @@
+package emailverifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// mockRDAPBootstrap mocks a single fetch of the IANA bootstrap registry
+func mockRDAPBootstrap() {
+	gock.New("https://data.iana.org").
+		Get("/rdap/dns.json").
+		Times(1).
+		Reply(http.StatusOK).
+		BodyString(`{"version":"1.0","services":[` +
+			`[["com","net"],["https://rdap.verisign.com/com/v1/"]],` +
+			`[["org"],["http://rdap.publicinterestregistry.org/rdap","https://rdap.publicinterestregistry.org/rdap"]]]}`)
+}
+
+func TestCheckRDAP(t *testing.T) {
+	defer gock.Off()
+	mockRDAPBootstrap()
+	gock.New("https://rdap.publicinterestregistry.org").
+		Get("/rdap/domain/example.org").
+		MatchHeader("Accept", "application/rdap\\+json").
+		Reply(http.StatusOK).
+		BodyString(`{"objectClassName":"domain","ldhName":"example.org",` +
+			`"status":["client hold","pending delete"],` +
+			`"events":[{"eventAction":"registration","eventDate":"1995-08-31T04:00:00Z"},` +
+			`{"eventAction":"expiration","eventDate":"2030-08-30T04:00:00Z"},` +
+			`{"eventAction":"last changed","eventDate":"2024-08-02T02:17:34Z"}],` +
+			`"entities":[{"objectClassName":"entity","roles":["registrar"],` +
+			`"vcardArray":["vcard",[["version",{},"text","4.0"],["fn",{},"text","Example Registrar, Inc."]]]}]}`)
+
+	rdap, err := NewVerifier().CheckRDAP("Example.org")
+	assert.NoError(t, err)
+	assert.Equal(t, "example.org", rdap.Domain)
+	assert.Equal(t, "Example Registrar, Inc.", rdap.Registrar)
+	assert.Equal(t, time.Date(1995, 8, 31, 4, 0, 0, 0, time.UTC), rdap.Registered.UTC())
+	assert.Equal(t, time.Date(2030, 8, 30, 4, 0, 0, 0, time.UTC), rdap.Expires.UTC())
+	assert.Equal(t, time.Date(2024, 8, 2, 2, 17, 34, 0, time.UTC), rdap.LastChanged.UTC())
+	assert.True(t, rdap.HasStatus("clientHold"))
+	assert.True(t, rdap.HasStatus("pending delete"))
+	assert.False(t, rdap.HasStatus("active"))
+}
+
+func TestCheckRDAP_NotSupported(t *testing.T) {
+	defer gock.Off()
+	mockRDAPBootstrap()
+
+	v := NewVerifier()
+	_, err := v.CheckRDAP("example.io")
+	assert.Equal(t, ErrRDAPNotSupported, err)
+
+	// the registry is fetched once only
+	_, err = v.CheckRDAP("sub.example.io")
+	assert.Equal(t, ErrRDAPNotSupported, err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestCheckRDAP_NotFound(t *testing.T) {
+	defer gock.Off()
+	mockRDAPBootstrap()
+	gock.New("https://rdap.verisign.com").
+		Get("/com/v1/domain/unregistered.com").
+		Reply(http.StatusNotFound)
+
+	_, err := NewVerifier().CheckRDAP("unregistered.com")
+	assert.Equal(t, ErrRDAPNotFound, err)
+}
+
+func TestCheckRDAP_BootstrapFailed(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://data.iana.org").
+		Get("/rdap/dns.json").
+		Reply(http.StatusInternalServerError)
+
+	_, err := NewVerifier().CheckRDAP("example.com")
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrRDAPNotSupported, err)
+}
+
+func TestRDAPBootstrap_StaleDuringFetch(t *testing.T) {
+	requested, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-release
+		_, _ = w.Write([]byte(`{"services":[[["com"],["https://rdap.new.example/"]]]}`))
+	}))
+	defer srv.Close()
+
+	b := newRDAPBootstrap(srv.URL, time.Hour)
+	b.servers = map[string]string{"com": "https://rdap.old.example/"}
+	b.expires = time.Now().Add(-time.Minute)
+
+	fetched := make(chan string)
+	go func() {
+		server, _ := b.lookup(context.Background(), srv.Client(), "example.com")
+		fetched <- server
+	}()
+	<-requested
+
+	// The fetch in flight does not block the other lookups
+	server, err := b.lookup(context.Background(), srv.Client(), "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://rdap.old.example/", server)
+
+	close(release)
+	assert.Equal(t, "https://rdap.new.example/", <-fetched)
+	server, err = b.lookup(context.Background(), srv.Client(), "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://rdap.new.example/", server)
+}
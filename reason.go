@@ -0,0 +1,121 @@
+package emailverifier
+
+import (
+	"errors"
+	"net"
+)
+
+// The determining factors of the verification outcome, reported in Result.Reason
+const (
+	ReasonInvalidSyntax     = "invalid_syntax"
+	ReasonBlocklisted       = "blocklisted"
+	ReasonAllowlisted       = "allowlisted"
+	ReasonDisposable        = "disposable_domain"
+	ReasonParkedDomain      = "parked_domain"
+	ReasonRoleAccount       = "role_account"
+	ReasonFreeDomain        = "free_domain"
+	ReasonNoMXRecords       = "no_mx_records"
+	ReasonDomainNotFound    = "domain_not_found"
+	ReasonDNSError          = "dns_error"
+	ReasonSMTPCheckDisabled = "smtp_check_disabled"
+	ReasonCatchAll          = "catch_all"
+	ReasonMailGateway       = "mail_gateway"
+	ReasonUserUnknown       = "smtp_550_user_unknown"
+	ReasonMailboxDisabled   = "smtp_mailbox_disabled"
+	ReasonFullInbox         = "smtp_full_inbox"
+	ReasonSenderBlocked     = "smtp_sender_blocked"
+	ReasonGreylisted        = "smtp_greylisted"
+	ReasonTryAgainLater     = "smtp_try_again_later"
+	ReasonTimeout           = "smtp_timeout"
+	ReasonConnectionFailed  = "smtp_connection_failed"
+	ReasonSMTPUTF8          = "smtp_utf8_unsupported"
+	ReasonAPIRateLimited    = "api_rate_limited"
+	ReasonSMTPError         = "smtp_error"
+	ReasonVerifyTimeout     = "verify_timeout"
+)
+
+// reason explains the outcome of Verify, which returned the error, by its determining factor.
+// It is empty for a reachable address.
+func (v *Verifier) reason(ret *Result, err error) string {
+	switch {
+	case !ret.Syntax.Valid:
+		return ReasonInvalidSyntax
+	case ret.Disposable:
+		return ReasonDisposable
+	case ret.Reachable == reachableYes:
+		return ""
+	case ret.Parked:
+		return ReasonParkedDomain
+	case ret.SMTP != nil:
+		return smtpReason(ret.SMTP)
+	case errors.Is(err, ErrVerifyTimeout):
+		return ReasonVerifyTimeout
+	case err == nil && !v.smtpCheckEnabled:
+		return ReasonSMTPCheckDisabled
+	case err == nil:
+		return ""
+	case !ret.HasMxRecords:
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && !dnsErr.IsNotFound {
+			return ReasonDNSError
+		}
+		return ReasonNoMXRecords
+	default:
+		return smtpErrorReason(err)
+	}
+}
+
+// smtpReason explains the outcome of the SMTP check, which did not fail
+func smtpReason(s *SMTP) string {
+	switch {
+	case s.Deliverable && s.GatewayDetected:
+		// Reported as unknown when accepted by a gateway, see EnableGatewayDowngrade
+		return ReasonMailGateway
+	case s.Deliverable:
+		return ""
+	case s.SenderBlocked:
+		return ReasonSenderBlocked
+	case s.CatchAllStatus == CatchAllYes || s.CatchAll:
+		return ReasonCatchAll
+	default:
+		return ReasonUserUnknown
+	}
+}
+
+// smtpErrorReason explains the failure of the SMTP check
+func smtpErrorReason(err error) string {
+	if isAPIRateLimitError(err) {
+		return ReasonAPIRateLimited
+	}
+	var e *LookupError
+	if !errors.As(err, &e) || e == nil {
+		return ReasonSMTPError
+	}
+	switch e.Message {
+	case ErrServerUnavailable:
+		if e.Code >= 500 {
+			return ReasonUserUnknown
+		}
+		return ReasonConnectionFailed
+	case ErrNoSuchHost:
+		return ReasonConnectionFailed
+	case ErrNoMXRecords:
+		return ReasonNoMXRecords
+	case ErrTimeout:
+		return ReasonTimeout
+	case ErrBlocked:
+		return ReasonSenderBlocked
+	case ErrNotAllowed:
+		return ReasonMailboxDisabled
+	case ErrFullInbox:
+		return ReasonFullInbox
+	case ErrGreylisted:
+		return ReasonGreylisted
+	case ErrSMTPUTF8Unsupported:
+		return ReasonSMTPUTF8
+	case ErrTryAgainLater, ErrMailboxBusy, ErrExceededMessagingLimits, ErrTooManyRCPT:
+		return ReasonTryAgainLater
+	default:
+		return ReasonSMTPError
+	}
+}
@@ -0,0 +1,80 @@
+package emailverifier
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify_Reason(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		if cmd != "RCPT" {
+			return ""
+		}
+		switch arg {
+		case "TO:<someone@example.test>":
+			return "250 OK"
+		case "TO:<blocked@example.test>":
+			return "550 5.7.1 Client host blocked using zen.spamhaus.org"
+		case "TO:<full@example.test>":
+			return "552 5.2.2 Mailbox full"
+		case "TO:<later@example.test>":
+			return "451 4.3.0 Temporary failure"
+		}
+		return "550 5.1.1 user unknown"
+	}
+	v := srv.verifier().EnableMXResolver(&mockResolver{
+		mx: map[string][]*net.MX{"example.test": {{Host: "mx.example.test.", Pref: 10}}},
+		ns: map[string][]*net.NS{"nomx.test": {{Host: "ns.nomx.test."}}},
+	})
+
+	cases := map[string]string{
+		"someone@example.test": "",
+		"nobody@example.test":  ReasonUserUnknown,
+		"blocked@example.test": ReasonSenderBlocked,
+		"full@example.test":    ReasonFullInbox,
+		"later@example.test":   ReasonTryAgainLater,
+		"someone@nomx.test":    ReasonNoMXRecords,
+		"someone@nx.test":      ReasonDomainNotFound,
+		"invalid":              ReasonInvalidSyntax,
+	}
+	for email, reason := range cases {
+		ret, _ := v.Verify(email)
+		assert.Equal(t, reason, ret.Reason, email)
+	}
+
+	ret, err := srv.verifier().DisableSMTPCheck().EnableMXResolver(&mockResolver{mx: map[string][]*net.MX{
+		"example.test": {{Host: "mx.example.test.", Pref: 10}},
+	}}).Verify("someone@example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, ReasonSMTPCheckDisabled, ret.Reason)
+}
+
+func TestVerify_ReasonDNSError(t *testing.T) {
+	resolver := &flakyResolver{
+		mockResolver: &mockResolver{},
+		failures:     1,
+		err:          &net.DNSError{Err: "server misbehaving", Name: "example.test", IsTemporary: true},
+	}
+
+	ret, err := NewVerifier().EnableMXResolver(resolver).Verify("someone@example.test")
+	assert.Error(t, err)
+	assert.Equal(t, ReasonDNSError, ret.Reason)
+}
+
+func TestSMTPErrorReason(t *testing.T) {
+	cases := map[string]error{
+		ReasonTimeout:          newLookupError(0, ErrTimeout, "i/o timeout"),
+		ReasonConnectionFailed: newLookupError(0, ErrNoSuchHost, "no such host"),
+		ReasonMailboxDisabled:  newLookupError(554, ErrNotAllowed, "554 5.7.1 mailbox disabled"),
+		ReasonGreylisted:       newLookupError(451, ErrGreylisted, "451 greylisted"),
+		ReasonAPIRateLimited:   &APIRateLimitError{errors.New("throttled")},
+		ReasonSMTPError:        errors.New("unexpected"),
+	}
+	for reason, err := range cases {
+		assert.Equal(t, reason, smtpErrorReason(err), err.Error())
+	}
+}
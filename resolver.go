@@ -0,0 +1,259 @@
+package emailverifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DoHCloudflare is the DNS-over-HTTPS JSON endpoint of Cloudflare
+	DoHCloudflare = "https://cloudflare-dns.com/dns-query"
+	// DoHGoogle is the DNS-over-HTTPS JSON endpoint of Google
+	DoHGoogle = "https://dns.google/resolve"
+
+	dnsTypeMX  = 15
+	dnsTypeTXT = 16
+
+	dnsStatusServFail = 2
+	dnsStatusNXDomain = 3
+)
+
+// MXResolver resolves the MX records of a domain, *net.Resolver satisfies it
+type MXResolver interface {
+	LookupMX(ctx context.Context, domain string) ([]*net.MX, error)
+}
+
+// txtResolver is implemented by resolvers which can also resolve TXT records,
+// net.DefaultResolver is used for TXT lookups otherwise
+type txtResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// hostResolver is implemented by resolvers which can also resolve A/AAAA records,
+// net.DefaultResolver is used for host lookups otherwise
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// addrResolver is implemented by resolvers which can also resolve PTR records,
+// net.DefaultResolver is used for reverse lookups otherwise
+type addrResolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+}
+
+// nsResolver is implemented by resolvers which can also resolve NS records,
+// net.DefaultResolver is used for NS lookups otherwise
+type nsResolver interface {
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+}
+
+// ttlMXResolver is implemented by resolvers which know the TTL of the DNS answer,
+// the TTL bounds the lifetime of the MX cache entries
+type ttlMXResolver interface {
+	LookupMXWithTTL(ctx context.Context, domain string) ([]*net.MX, time.Duration, error)
+}
+
+// DoHResolver resolves MX records over HTTPS using the DNS JSON API,
+// e.g. when UDP/53 is blocked in restrictive networks
+type DoHResolver struct {
+	endpoint string
+	client   *http.Client
+}
+
+type dohResponse struct {
+	Status int         `json:"Status"`
+	Answer []dohAnswer `json:"Answer"`
+}
+
+type dohAnswer struct {
+	Type int    `json:"type"`
+	TTL  int    `json:"TTL"`
+	Data string `json:"data"`
+}
+
+// NewDoHResolver creates a DNS-over-HTTPS resolver for the endpoint (e.g. DoHCloudflare or DoHGoogle),
+// http.DefaultClient is used when client is nil
+func NewDoHResolver(endpoint string, client *http.Client) *DoHResolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &DoHResolver{
+		endpoint: endpoint,
+		client:   client,
+	}
+}
+
+// LookupMX returns the DNS MX records of the domain sorted by preference
+func (r *DoHResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	records, _, err := r.LookupMXWithTTL(ctx, domain)
+	return records, err
+}
+
+// LookupMXWithTTL returns the DNS MX records of the domain sorted by preference
+// together with the lowest TTL of the answer
+func (r *DoHResolver) LookupMXWithTTL(ctx context.Context, domain string) ([]*net.MX, time.Duration, error) {
+	answers, err := r.query(ctx, domain, "MX")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var records []*net.MX
+	var ttl time.Duration
+	for _, a := range answers {
+		if a.Type != dnsTypeMX {
+			continue
+		}
+		mx, err := parseMXData(a.Data)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, mx)
+
+		answerTTL := time.Duration(a.TTL) * time.Second
+		if ttl == 0 || answerTTL < ttl {
+			ttl = answerTTL
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Pref < records[j].Pref
+	})
+
+	return records, ttl, nil
+}
+
+// LookupTXT returns the DNS TXT records of the name
+func (r *DoHResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	answers, err := r.query(ctx, name, "TXT")
+	if err != nil {
+		return nil, err
+	}
+
+	var records []string
+	for _, a := range answers {
+		if a.Type == dnsTypeTXT {
+			records = append(records, parseTXTData(a.Data))
+		}
+	}
+	return records, nil
+}
+
+// query sends the DNS question to the DoH endpoint and returns the answers
+func (r *DoHResolver) query(ctx context.Context, name, qtype string) ([]dohAnswer, error) {
+	u, err := url.Parse(r.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("name", name)
+	q.Set("type", qtype)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/dns-json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, Server: r.endpoint, IsTemporary: true}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &net.DNSError{
+			Err:         fmt.Sprintf("DNS-over-HTTPS query failed with status_code: %d", resp.StatusCode),
+			Name:        name,
+			Server:      r.endpoint,
+			IsTemporary: resp.StatusCode >= http.StatusInternalServerError,
+		}
+	}
+
+	var res dohResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+
+	switch res.Status {
+	case 0:
+		return res.Answer, nil
+	case dnsStatusNXDomain:
+		return nil, &net.DNSError{Err: "no such host", Name: name, Server: r.endpoint, IsNotFound: true}
+	case dnsStatusServFail:
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name, Server: r.endpoint, IsTemporary: true}
+	default:
+		return nil, &net.DNSError{Err: fmt.Sprintf("DNS query failed with rcode: %d", res.Status), Name: name, Server: r.endpoint}
+	}
+}
+
+// parseMXData parses the "<preference> <host>" data of an MX answer
+func parseMXData(data string) (*net.MX, error) {
+	fields := strings.Fields(data)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("malformed MX record: %q", data)
+	}
+	pref, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("malformed MX record: %q", data)
+	}
+
+	host := fields[1]
+	if !strings.HasSuffix(host, ".") {
+		host += "."
+	}
+	return &net.MX{Host: host, Pref: uint16(pref)}, nil
+}
+
+// parseTXTData joins the quoted character-strings of a TXT answer
+func parseTXTData(data string) string {
+	if !strings.HasPrefix(data, `"`) {
+		return data
+	}
+
+	var sb strings.Builder
+	for _, part := range strings.Split(data, `" "`) {
+		part = strings.TrimSuffix(strings.TrimPrefix(part, `"`), `"`)
+		sb.WriteString(strings.Replace(part, `\"`, `"`, -1))
+	}
+	return sb.String()
+}
+
+// lookupTXT resolves the TXT records of the name with the configured resolver when it supports TXT lookups
+func (v *Verifier) lookupTXT(name string) ([]string, error) {
+	if r, ok := v.mxResolver.(txtResolver); ok {
+		return r.LookupTXT(v.context(), name)
+	}
+	return net.DefaultResolver.LookupTXT(v.context(), name)
+}
+
+// lookupHost resolves the addresses of the host with the configured resolver when it supports host lookups
+func (v *Verifier) lookupHost(host string) ([]string, error) {
+	if r, ok := v.mxResolver.(hostResolver); ok {
+		return r.LookupHost(v.context(), host)
+	}
+	return net.DefaultResolver.LookupHost(v.context(), host)
+}
+
+// lookupAddr resolves the host names of the address with the configured resolver when it supports reverse lookups
+func (v *Verifier) lookupAddr(addr string) ([]string, error) {
+	if r, ok := v.mxResolver.(addrResolver); ok {
+		return r.LookupAddr(v.context(), addr)
+	}
+	return net.DefaultResolver.LookupAddr(v.context(), addr)
+}
+
+// lookupNS resolves the name servers of the name with the configured resolver when it supports NS lookups
+func (v *Verifier) lookupNS(name string) ([]*net.NS, error) {
+	if r, ok := v.mxResolver.(nsResolver); ok {
+		return r.LookupNS(v.context(), name)
+	}
+	return net.DefaultResolver.LookupNS(v.context(), name)
+}
@@ -0,0 +1,133 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newDoHServer(t *testing.T, status int, answers string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "MX", r.URL.Query().Get("type"))
+		assert.Equal(t, "application/dns-json", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "application/dns-json")
+		_, _ = fmt.Fprintf(w, `{"Status":%d,"Answer":[%s]}`, status, answers)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDoHResolverOK(t *testing.T) {
+	srv := newDoHServer(t, 0, `
+		{"name":"example.com.","type":15,"TTL":300,"data":"20 alt.mx.example.com."},
+		{"name":"example.com.","type":15,"TTL":120,"data":"10 mx.example.com"},
+		{"name":"example.com.","type":5,"TTL":60,"data":"other.example.com."}`)
+
+	r := NewDoHResolver(srv.URL, nil)
+	records, ttl, err := r.LookupMXWithTTL(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []*net.MX{
+		{Host: "mx.example.com.", Pref: 10},
+		{Host: "alt.mx.example.com.", Pref: 20},
+	}, records)
+	assert.Equal(t, 120*time.Second, ttl)
+}
+
+func TestDoHResolverFailed_NXDomain(t *testing.T) {
+	srv := newDoHServer(t, dnsStatusNXDomain, "")
+
+	_, err := NewDoHResolver(srv.URL, nil).LookupMX(context.Background(), "example.com")
+	var dnsErr *net.DNSError
+	assert.True(t, errors.As(err, &dnsErr))
+	assert.True(t, dnsErr.IsNotFound)
+	assert.Equal(t, ErrNoSuchHost, ParseSMTPError(err).Message)
+}
+
+func TestDoHResolverFailed_MalformedAnswer(t *testing.T) {
+	srv := newDoHServer(t, 0, `{"type":15,"TTL":300,"data":"mx.example.com."}`)
+
+	_, err := NewDoHResolver(srv.URL, nil).LookupMX(context.Background(), "example.com")
+	assert.Error(t, err)
+}
+
+func TestCheckMXOK_DoHResolverWithCache(t *testing.T) {
+	srv := newDoHServer(t, 0, `{"type":15,"TTL":1,"data":"10 mx.example.com."}`)
+
+	verifier := NewVerifier().EnableMXResolver(NewDoHResolver(srv.URL, srv.Client())).EnableMXCache(time.Hour)
+	mx, err := verifier.CheckMX("example.com")
+	assert.NoError(t, err)
+	assert.True(t, mx.HasMXRecord)
+
+	verifier.mxCache.mu.RLock()
+	expires := verifier.mxCache.entries["example.com"].expires
+	verifier.mxCache.mu.RUnlock()
+	assert.WithinDuration(t, time.Now().Add(time.Second), expires, time.Second)
+}
+
+// mockResolver resolves fixed records, unknown names are reported as not found
+type mockResolver struct {
+	mx    map[string][]*net.MX
+	txt   map[string][]string
+	hosts map[string][]string
+	addrs map[string][]string
+	ns    map[string][]*net.NS
+}
+
+func (r *mockResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	if records, ok := r.mx[domain]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+}
+
+func (r *mockResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if records, ok := r.txt[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *mockResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r *mockResolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	if records, ok := r.ns[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *mockResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	if names, ok := r.addrs[addr]; ok {
+		return names, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+}
+
+func TestDoHResolverOK_TXT(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "TXT", r.URL.Query().Get("type"))
+		_, _ = fmt.Fprint(w, `{"Status":0,"Answer":[{"type":16,"TTL":300,"data":"\"v=DMARC1; \" \"p=reject\""}]}`)
+	}))
+	defer srv.Close()
+
+	records, err := NewDoHResolver(srv.URL, nil).LookupTXT(context.Background(), "_dmarc.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v=DMARC1; p=reject"}, records)
+}
+
+func TestEnableMXResolver_Nil(t *testing.T) {
+	v := NewVerifier().EnableMXResolver(&mockResolver{}).EnableMXResolver(nil)
+	assert.Equal(t, net.DefaultResolver, v.mxResolver)
+}
@@ -0,0 +1,202 @@
+package emailverifier
+
+import (
+	"sync"
+	"time"
+)
+
+// ResultCacheTTL holds the lifetimes of the cached results by their outcome,
+// a zero lifetime does not cache such results
+type ResultCacheTTL struct {
+	Reachable   time.Duration // results with reachable "yes"
+	Unreachable time.Duration // addresses rejected for good, e.g. unknown users or domains without MX records
+	Unknown     time.Duration // the other results, e.g. catch-all domains
+}
+
+// of returns the lifetime of the result, zero when the result is not cached at all,
+// i.e. the check failed for a reason other than the rejection of the address
+func (t ResultCacheTTL) of(r *Result, err error) time.Duration {
+	switch {
+	case isUnreachableResult(r):
+		return t.Unreachable
+	case err != nil:
+		return 0
+	case r.Reachable == reachableYes:
+		return t.Reachable
+	default:
+		return t.Unknown
+	}
+}
+
+// isUnreachableResult checks if the address of the result is rejected for good
+func isUnreachableResult(r *Result) bool {
+	if r.Reachable == reachableNo {
+		return true
+	}
+	switch r.Reason {
+	case ReasonUserUnknown, ReasonMailboxDisabled, ReasonNoMXRecords:
+		return true
+	}
+	return false
+}
+
+// resultCache is a goroutine-safe in-memory cache of the results of Verify keyed by the normalized email
+type resultCache struct {
+	mu      sync.RWMutex
+	ttl     ResultCacheTTL
+	entries map[string]resultCacheEntry
+}
+
+type resultCacheEntry struct {
+	result  Result
+	err     error
+	expires time.Time
+}
+
+// newResultCache creates a new result cache whose entries live by ttl
+func newResultCache(ttl ResultCacheTTL) *resultCache {
+	return &resultCache{
+		ttl:     ttl,
+		entries: map[string]resultCacheEntry{},
+	}
+}
+
+// get returns the cached result of the email and its error, if it has not expired yet
+func (c *resultCache) get(email string) (resultCacheEntry, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[email]
+	c.mu.RUnlock()
+	if !ok {
+		return resultCacheEntry{}, false
+	}
+
+	if time.Now().After(e.expires) {
+		c.mu.Lock()
+		if e, ok = c.entries[email]; ok && time.Now().After(e.expires) {
+			delete(c.entries, email)
+		}
+		c.mu.Unlock()
+		return resultCacheEntry{}, false
+	}
+	e.result = cloneResult(&e.result)
+	return e, true
+}
+
+// set stores a deep copy of the result of the email and its error, unless its lifetime is zero
+func (c *resultCache) set(email string, r *Result, err error) {
+	ttl := c.ttl.of(r, err)
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	c.entries[email] = resultCacheEntry{
+		result:  cloneResult(r),
+		err:     err,
+		expires: time.Now().Add(ttl),
+	}
+	c.mu.Unlock()
+}
+
+// cloneResult returns a deep copy of the result, so the cached result shares nothing with the callers
+func cloneResult(r *Result) Result {
+	c := *r
+	if r.SMTP != nil {
+		smtp := *r.SMTP
+		if r.SMTP.Extensions != nil {
+			smtp.Extensions = make(map[string]string, len(r.SMTP.Extensions))
+			for k, v := range r.SMTP.Extensions {
+				smtp.Extensions[k] = v
+			}
+		}
+		smtp.Transcript = append([]SMTPStep(nil), r.SMTP.Transcript...)
+		if r.SMTP.VRFY != nil {
+			vrfy := *r.SMTP.VRFY
+			smtp.VRFY = &vrfy
+		}
+		c.SMTP = &smtp
+	}
+	if r.Gravatar != nil {
+		gravatar := *r.Gravatar
+		if r.Gravatar.Profile != nil {
+			profile := *r.Gravatar.Profile
+			profile.URLs = append([]GravatarURL(nil), r.Gravatar.Profile.URLs...)
+			profile.Accounts = append([]GravatarAccount(nil), r.Gravatar.Profile.Accounts...)
+			gravatar.Profile = &profile
+		}
+		c.Gravatar = &gravatar
+	}
+	if r.DMARC != nil {
+		dmarc := *r.DMARC
+		dmarc.AggregateReports = append([]string(nil), r.DMARC.AggregateReports...)
+		dmarc.ForensicReports = append([]string(nil), r.DMARC.ForensicReports...)
+		c.DMARC = &dmarc
+	}
+	if r.DomainAge != nil {
+		age := *r.DomainAge
+		c.DomainAge = &age
+	}
+	if r.MailServices != nil {
+		services := *r.MailServices
+		c.MailServices = &services
+	}
+	return c
+}
+
+// delete removes the cached result of the email
+func (c *resultCache) delete(email string) {
+	c.mu.Lock()
+	delete(c.entries, email)
+	c.mu.Unlock()
+}
+
+// clear removes all the cached entries
+func (c *resultCache) clear() {
+	c.mu.Lock()
+	c.entries = map[string]resultCacheEntry{}
+	c.mu.Unlock()
+}
+
+// EnableResultCache caches the results of Verify per normalized email (see NormalizeEmail) for ttl,
+// so verifying the same address again within the ttl repeats neither the DNS lookups nor the SMTP check.
+// The failed checks are not cached, unless the address was rejected for good, e.g. by ReasonUserUnknown.
+func (v *Verifier) EnableResultCache(ttl time.Duration) *Verifier {
+	return v.EnableResultCacheTTL(ResultCacheTTL{Reachable: ttl, Unreachable: ttl, Unknown: ttl})
+}
+
+// EnableResultCacheTTL is like EnableResultCache, but the lifetime of the cached results depends on their
+// outcome, e.g. the unreachable addresses may be cached longer than the unknown ones
+func (v *Verifier) EnableResultCacheTTL(ttl ResultCacheTTL) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.resultCache = newResultCache(ttl)
+	return v
+}
+
+// DisableResultCache disables the result cache
+func (v *Verifier) DisableResultCache() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.resultCache = nil
+	return v
+}
+
+// InvalidateResult removes the cached result of the email, so the next Verify checks it again
+func (v *Verifier) InvalidateResult(email string) {
+	v = v.snapshot()
+	if v.resultCache != nil {
+		v.resultCache.delete(v.normalizeEmail(email))
+	}
+}
+
+// ClearResultCache removes all the cached results
+func (v *Verifier) ClearResultCache() {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if v.resultCache != nil {
+		v.resultCache.clear()
+	}
+}
@@ -0,0 +1,135 @@
+package emailverifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultCacheOK(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier("gmail.com").EnableResultCache(time.Minute)
+
+	ret, err := verifier.Verify("john.doe+news@gmail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableYes, ret.Reachable)
+
+	// The same address normalized
+	ret, err = verifier.Verify("johndoe@gmail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "johndoe@gmail.com", ret.Email)
+	assert.Equal(t, reachableYes, ret.Reachable)
+	assert.Equal(t, "johndoe", ret.Syntax.Username)
+	assert.False(t, ret.Syntax.HasPlusTag)
+	assert.Empty(t, ret.Syntax.Tag)
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 1)
+
+	verifier.InvalidateResult("john.doe@gmail.com")
+	_, err = verifier.Verify("johndoe@gmail.com")
+	assert.NoError(t, err)
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 2)
+
+	verifier.ClearResultCache()
+	_, err = verifier.Verify("johndoe@gmail.com")
+	assert.NoError(t, err)
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 3)
+}
+
+func TestResultCache_TTLByReachability(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("john@gmail.com")
+	verifier := srv.verifier("gmail.com").EnableResultCacheTTL(ResultCacheTTL{Reachable: time.Minute})
+
+	// The rejected address is not cached without the unreachable TTL
+	for i := 0; i < 2; i++ {
+		ret, err := verifier.Verify("jane@gmail.com")
+		assert.Error(t, err)
+		assert.Equal(t, ReasonUserUnknown, ret.Reason)
+	}
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 2)
+
+	verifier.EnableResultCacheTTL(ResultCacheTTL{Unreachable: time.Minute})
+	for i := 0; i < 2; i++ {
+		ret, err := verifier.Verify("jane@gmail.com")
+		var smtpErr *SMTPError
+		assert.ErrorAs(t, err, &smtpErr)
+		assert.Equal(t, ReasonUserUnknown, ret.Reason)
+	}
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 3)
+}
+
+func TestResultCache_Expired(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier("gmail.com").EnableResultCache(time.Millisecond)
+
+	_, err := verifier.Verify("john@gmail.com")
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = verifier.Verify("john@gmail.com")
+	assert.NoError(t, err)
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 2)
+}
+
+func TestResultCache_ErrorNotCached(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "RCPT" {
+			return "421 4.7.0 try again later"
+		}
+		return ""
+	}
+	verifier := srv.verifier("gmail.com").EnableResultCache(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, err := verifier.Verify("john@gmail.com")
+		assert.Error(t, err)
+	}
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 2)
+}
+
+func TestResultCache_Copied(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier("gmail.com").EnableResultCache(time.Minute)
+
+	ret, err := verifier.Verify("john@gmail.com")
+	assert.NoError(t, err)
+	ret.SMTP.Deliverable = false
+
+	ret, err = verifier.Verify("john@gmail.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.Deliverable)
+	ret.SMTP.Deliverable = false
+
+	ret, err = verifier.Verify("john@gmail.com")
+	assert.NoError(t, err)
+	assert.True(t, ret.SMTP.Deliverable)
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 1)
+}
+
+func TestCloneResult(t *testing.T) {
+	r := &Result{
+		SMTP:         &SMTP{Extensions: map[string]string{"SIZE": "1"}, Transcript: []SMTPStep{{Code: 250}}, VRFY: &SMTPVRFY{Code: 252}},
+		Gravatar:     &Gravatar{Profile: &GravatarProfile{URLs: []GravatarURL{{Value: "a"}}}},
+		DMARC:        &DMARC{AggregateReports: []string{"a"}},
+		DomainAge:    &DomainAge{Days: 1},
+		MailServices: &MailServices{IMAP: true},
+	}
+	c := cloneResult(r)
+	assert.Equal(t, *r, c)
+
+	c.SMTP.Extensions["SIZE"] = "2"
+	c.SMTP.Transcript[0].Code = 550
+	c.SMTP.VRFY.Code = 502
+	c.Gravatar.Profile.URLs[0].Value = "b"
+	c.DMARC.AggregateReports[0] = "b"
+	c.DomainAge.Days = 2
+	c.MailServices.IMAP = false
+	assert.Equal(t, "1", r.SMTP.Extensions["SIZE"])
+	assert.Equal(t, 250, r.SMTP.Transcript[0].Code)
+	assert.Equal(t, 252, r.SMTP.VRFY.Code)
+	assert.Equal(t, "a", r.Gravatar.Profile.URLs[0].Value)
+	assert.Equal(t, "a", r.DMARC.AggregateReports[0])
+	assert.Equal(t, 1, r.DomainAge.Days)
+	assert.True(t, r.MailServices.IMAP)
+}
@@ -0,0 +1,46 @@
+package emailverifier
+
+import "encoding/json"
+
+// ResultVersion is the version of the JSON format of Result, reported in its "version" field.
+// It is increased whenever a change of the format may break its consumers.
+const ResultVersion = 1
+
+// MarshalJSON encodes the result along with the version of the format,
+// the details of the checks which were not performed are omitted
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result // drops the methods, so the encoding does not recurse
+	return json.Marshal(struct {
+		Version int `json:"version"`
+		result
+		SMTP      *SMTP      `json:"smtp,omitempty"`
+		Gravatar  *Gravatar  `json:"gravatar,omitempty"`
+		DMARC     *DMARC     `json:"dmarc,omitempty"`
+		DomainAge *DomainAge `json:"domain_age,omitempty"`
+	}{
+		Version:   ResultVersion,
+		result:    result(r),
+		SMTP:      r.SMTP,
+		Gravatar:  r.Gravatar,
+		DMARC:     r.DMARC,
+		DomainAge: r.DomainAge,
+	})
+}
+
+// UnmarshalJSON decodes the result of any version, the missing fields are left zero
+// except the reachability, which is unknown unless reported
+func (r *Result) UnmarshalJSON(data []byte) error {
+	type result Result
+	aux := struct {
+		Version int `json:"version"`
+		*result
+	}{result: (*result)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if r.Reachable == "" {
+		r.Reachable = reachableUnknown
+	}
+	return nil
+}
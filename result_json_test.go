@@ -0,0 +1,58 @@
+package emailverifier
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultMarshalJSON(t *testing.T) {
+	ret := Result{
+		Email:     "user@example.org",
+		Reachable: reachableYes,
+		Syntax:    Syntax{Username: "user", Domain: "example.org", Valid: true},
+		SMTP:      &SMTP{HostExists: true, Deliverable: true},
+	}
+
+	data, err := json.Marshal(ret)
+	assert.NoError(t, err)
+
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "1", string(fields["version"]))
+	assert.Contains(t, fields, "smtp")
+	assert.NotContains(t, fields, "gravatar")
+	assert.NotContains(t, fields, "dmarc")
+	assert.NotContains(t, fields, "domain_age")
+
+	// a pointer encodes the same way
+	ptrData, err := json.Marshal(&ret)
+	assert.NoError(t, err)
+	assert.Equal(t, data, ptrData)
+}
+
+func TestResultUnmarshalJSON(t *testing.T) {
+	ret := Result{
+		Email:     "user@example.org",
+		Reachable: reachableNo,
+		Gravatar:  &Gravatar{HasGravatar: true},
+		Score:     42,
+	}
+	data, err := json.Marshal(ret)
+	assert.NoError(t, err)
+
+	var decoded Result
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, ret, decoded)
+}
+
+func TestResultUnmarshalJSON_MissingFields(t *testing.T) {
+	var ret Result
+	assert.NoError(t, json.Unmarshal([]byte(`{"email":"user@example.org"}`), &ret))
+	assert.Equal(t, "user@example.org", ret.Email)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Nil(t, ret.SMTP)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"email":1}`), &ret))
+}
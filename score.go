@@ -0,0 +1,88 @@
+package emailverifier
+
+// ScoringWeights are the weights used to compute Result.Score.
+// The score is the sum of the weights of the signals which apply to the result,
+// clamped to 0-100; an address with invalid syntax always scores 0.
+type ScoringWeights struct {
+	ValidSyntax   int // the address syntax is valid
+	HasMXRecords  int // the domain has MX records
+	Deliverable   int // the SMTP check confirmed that the address is deliverable
+	CatchAll      int // the domain is a catch-all, so the address can not be confirmed
+	Undeliverable int // the SMTP check reported that the address is not deliverable
+	Disposable    int // the domain is a disposable one
+	RoleAccount   int // the username is a role-based account
+	Free          int // the domain is a free email domain
+	MailServices  int // the domain runs any of the mail access services, see EnableMailServicesCheck
+}
+
+// DefaultScoringWeights returns the weights used unless overridden by Verifier.ScoringWeights:
+// a valid address at a domain with MX records scores 50, 100 when confirmed deliverable,
+// 65 for a catch-all domain and 0 when rejected or disposable
+func DefaultScoringWeights() ScoringWeights {
+	return ScoringWeights{
+		ValidSyntax:   20,
+		HasMXRecords:  30,
+		Deliverable:   50,
+		CatchAll:      15,
+		Undeliverable: -50,
+		Disposable:    -60,
+		RoleAccount:   -10,
+		Free:          -5,
+		MailServices:  5,
+	}
+}
+
+// CalculateScore computes a 0-100 confidence score of the result from the collected signals
+func (v *Verifier) CalculateScore(r *Result) int {
+	return v.snapshot().calculateScore(r)
+}
+
+// calculateScore computes the confidence score of the result, see CalculateScore
+func (v *Verifier) calculateScore(r *Result) int {
+	if r == nil || !r.Syntax.Valid {
+		return 0
+	}
+
+	w := v.scoringWeights
+	score := w.ValidSyntax
+	if r.HasMxRecords {
+		score += w.HasMXRecords
+	}
+	if r.SMTP == nil && r.Reachable == reachableYes {
+		// Deliverable without the SMTP check, e.g. postmaster
+		score += w.Deliverable
+	}
+	if r.SMTP != nil {
+		switch {
+		case r.SMTP.Deliverable && r.SMTP.GatewayDetected && r.Reachable == reachableUnknown:
+			// Accepted by a gateway, which can not confirm the address like a catch-all
+			score += w.CatchAll
+		case r.SMTP.Deliverable:
+			score += w.Deliverable
+		case r.SMTP.CatchAll:
+			score += w.CatchAll
+		case r.SMTP.HostExists:
+			score += w.Undeliverable
+		}
+	}
+	if r.Disposable {
+		score += w.Disposable
+	}
+	if r.RoleAccount {
+		score += w.RoleAccount
+	}
+	if r.Free {
+		score += w.Free
+	}
+	if r.MailServices.open() {
+		score += w.MailServices
+	}
+
+	if score < 0 {
+		return 0
+	}
+	if score > 100 {
+		return 100
+	}
+	return score
+}
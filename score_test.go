@@ -0,0 +1,71 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateScore(t *testing.T) {
+	valid := Syntax{Username: "user", Domain: "example.com", Valid: true}
+	cases := []struct {
+		name     string
+		result   *Result
+		expected int
+	}{
+		{name: "nil result", result: nil, expected: 0},
+		{name: "invalid syntax", result: &Result{}, expected: 0},
+		{name: "valid syntax only", result: &Result{Syntax: valid}, expected: 20},
+		{name: "mx records", result: &Result{Syntax: valid, HasMxRecords: true}, expected: 50},
+		{
+			name:     "deliverable",
+			result:   &Result{Syntax: valid, HasMxRecords: true, SMTP: &SMTP{HostExists: true, Deliverable: true}},
+			expected: 100,
+		},
+		{
+			name:     "catch-all",
+			result:   &Result{Syntax: valid, HasMxRecords: true, SMTP: &SMTP{HostExists: true, CatchAll: true}},
+			expected: 65,
+		},
+		{
+			name:     "undeliverable",
+			result:   &Result{Syntax: valid, HasMxRecords: true, SMTP: &SMTP{HostExists: true}},
+			expected: 0,
+		},
+		{
+			name:     "free role account",
+			result:   &Result{Syntax: valid, HasMxRecords: true, SMTP: &SMTP{HostExists: true, Deliverable: true}, Free: true, RoleAccount: true},
+			expected: 85,
+		},
+		{name: "disposable", result: &Result{Syntax: valid, Disposable: true}, expected: 0},
+		{name: "mail services", result: &Result{Syntax: valid, HasMxRecords: true, MailServices: &MailServices{IMAP: true}}, expected: 55},
+		{name: "no mail services", result: &Result{Syntax: valid, HasMxRecords: true, MailServices: &MailServices{}}, expected: 50},
+	}
+
+	verifier := NewVerifier()
+	for _, c := range cases {
+		test := c
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, verifier.CalculateScore(test.result))
+		})
+	}
+}
+
+func TestCalculateScore_CustomWeights(t *testing.T) {
+	weights := DefaultScoringWeights()
+	weights.Free = -50
+	verifier := NewVerifier().ScoringWeights(weights)
+
+	r := &Result{Syntax: Syntax{Valid: true}, HasMxRecords: true, Free: true}
+	assert.Equal(t, 0, verifier.CalculateScore(r))
+}
+
+func TestVerifyOK_Score(t *testing.T) {
+	dr := newDisposableRepo()
+	dr.AddDisposableDomains([]string{"disposable.test"})
+	verifier := NewVerifier().EnableDisposableCheck(dr)
+
+	ret, err := verifier.Verify("user@disposable.test")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ret.Score)
+}
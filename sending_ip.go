@@ -0,0 +1,117 @@
+package emailverifier
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrSendingIPUnknown is returned by CheckSendingIPReputation when the IP the SMTP connections originate from
+// can not be determined, i.e. they go through a proxy or a custom dialer, see SendingIP
+var ErrSendingIPUnknown = errors.New("the sending IP can not be determined, set it via SendingIP")
+
+// SendingIPReputation is the diagnostic of the IP the SMTP connections originate from. Many servers refuse
+// the connections from the IPs without forward-confirmed reverse DNS, which shows as widespread ErrBlocked results.
+type SendingIPReputation struct {
+	IP             string   `json:"ip"`
+	Public         bool     `json:"public"`           // the IP is routable on the internet, a private one is translated by NAT
+	PTR            []string `json:"ptr"`              // host names of the IP (PTR records), without the trailing dot
+	FCrDNS         bool     `json:"fcrdns"`           // one of the host names resolves back to the IP (forward-confirmed reverse DNS)
+	HelloNameMatch bool     `json:"hello_name_match"` // the HELO name is one of the forward-confirmed host names
+	LikelyAccepted bool     `json:"likely_accepted"`  // the IP is public and has forward-confirmed reverse DNS
+}
+
+// SendingIP sets the public IP the SMTP connections originate from, checked by CheckSendingIPReputation.
+// It is needed behind NAT, a proxy or a custom dialer, otherwise the local address of the route to the internet is used.
+func (v *Verifier) SendingIP(ip string) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.sendingIP = ip
+	return v
+}
+
+// CheckSendingIPReputation checks whether the sending setup is likely to be accepted by the mail servers:
+// it looks up the PTR records of the sending IP and confirms that they resolve back to it
+func (v *Verifier) CheckSendingIPReputation() (*SendingIPReputation, error) {
+	v = v.snapshot()
+
+	ip, err := v.egressIP()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := SendingIPReputation{
+		IP:     ip.String(),
+		Public: ip.IsGlobalUnicast() && !ip.IsPrivate(),
+	}
+	if !ret.Public {
+		// The PTR records of the private IP say nothing about the address seen by the servers
+		return &ret, nil
+	}
+
+	names, err := v.lookupAddr(ret.IP)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return &ret, err
+	}
+
+	for _, name := range names {
+		name = strings.TrimSuffix(name, ".")
+		ret.PTR = append(ret.PTR, name)
+		if !v.resolvesTo(name, ip) {
+			continue
+		}
+		ret.FCrDNS = true
+		if strings.EqualFold(name, v.helloName) {
+			ret.HelloNameMatch = true
+		}
+	}
+	ret.LikelyAccepted = ret.FCrDNS
+
+	return &ret, nil
+}
+
+// egressIP returns the configured sending IP, or the local address of the route to the internet
+// when the SMTP connections are dialed directly
+func (v *Verifier) egressIP() (net.IP, error) {
+	if v.sendingIP != "" {
+		ip := net.ParseIP(v.sendingIP)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid sending IP %q", v.sendingIP)
+		}
+		return ip, nil
+	}
+	if v.dialerProvider != nil || v.proxyPool != nil || v.proxyURI != "" {
+		return nil, ErrSendingIPUnknown
+	}
+
+	// Connecting the UDP socket sends no packets, it only picks the route and so the local address,
+	// the address itself is a documentation one (RFC 5737, RFC 3849)
+	network, addr := "udp4", "192.0.2.1:25"
+	if v.dialNetwork == "tcp6" {
+		network, addr = "udp6", "[2001:db8::1]:25"
+	}
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSendingIPUnknown, err)
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
+
+// resolvesTo checks if the host resolves to the IP
+func (v *Verifier) resolvesTo(host string, ip net.IP) bool {
+	addrs, err := v.lookupHost(host)
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if ip.Equal(net.ParseIP(a)) {
+			return true
+		}
+	}
+	return false
+}
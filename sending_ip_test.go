@@ -0,0 +1,67 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSendingIPReputationOK(t *testing.T) {
+	verifier := NewVerifier().
+		SendingIP("203.0.113.5").
+		HelloName("mail.example.test").
+		EnableMXResolver(&mockResolver{
+			addrs: map[string][]string{"203.0.113.5": {"mail.example.test."}},
+			hosts: map[string][]string{"mail.example.test": {"203.0.113.5"}},
+		})
+
+	ret, err := verifier.CheckSendingIPReputation()
+	assert.NoError(t, err)
+	assert.Equal(t, &SendingIPReputation{
+		IP:             "203.0.113.5",
+		Public:         true,
+		PTR:            []string{"mail.example.test"},
+		FCrDNS:         true,
+		HelloNameMatch: true,
+		LikelyAccepted: true,
+	}, ret)
+}
+
+func TestCheckSendingIPReputation_NotConfirmed(t *testing.T) {
+	verifier := NewVerifier().
+		SendingIP("203.0.113.5").
+		EnableMXResolver(&mockResolver{
+			addrs: map[string][]string{"203.0.113.5": {"host-203-0-113-5.isp.example."}},
+			hosts: map[string][]string{"host-203-0-113-5.isp.example": {"203.0.113.6"}},
+		})
+
+	ret, err := verifier.CheckSendingIPReputation()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"host-203-0-113-5.isp.example"}, ret.PTR)
+	assert.False(t, ret.FCrDNS)
+	assert.False(t, ret.HelloNameMatch)
+	assert.False(t, ret.LikelyAccepted)
+}
+
+func TestCheckSendingIPReputation_NoPTR(t *testing.T) {
+	ret, err := NewVerifier().SendingIP("2001:db8::25").EnableMXResolver(&mockResolver{}).CheckSendingIPReputation()
+	assert.NoError(t, err)
+	assert.True(t, ret.Public)
+	assert.Empty(t, ret.PTR)
+	assert.False(t, ret.LikelyAccepted)
+}
+
+func TestCheckSendingIPReputation_PrivateIP(t *testing.T) {
+	ret, err := NewVerifier().SendingIP("10.0.0.25").EnableMXResolver(&mockResolver{}).CheckSendingIPReputation()
+	assert.NoError(t, err)
+	assert.False(t, ret.Public)
+	assert.False(t, ret.LikelyAccepted)
+}
+
+func TestCheckSendingIPReputation_Errors(t *testing.T) {
+	_, err := NewVerifier().Proxy("socks5://127.0.0.1:1080").CheckSendingIPReputation()
+	assert.ErrorIs(t, err, ErrSendingIPUnknown)
+
+	_, err = NewVerifier().SendingIP("mail.example.test").CheckSendingIPReputation()
+	assert.Error(t, err)
+}
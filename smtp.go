@@ -1,11 +1,13 @@
 package emailverifier
 
 import (
-	"context"
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"math/rand"
 	"net"
+	"net/http"
 	"net/smtp"
 	"net/url"
 	"strings"
@@ -16,109 +18,393 @@ import (
 
 // SMTP stores all information for SMTP verification lookup
 type SMTP struct {
-	HostExists  bool `json:"host_exists"` // is the host exists?
-	FullInbox   bool `json:"full_inbox"`  // is the email account's inbox full?
-	CatchAll    bool `json:"catch_all"`   // does the domain have a catch-all email address?
-	Deliverable bool `json:"deliverable"` // can send an email to the email server?
-	Disabled    bool `json:"disabled"`    // is the email blocked or disabled by the provider?
-	UsingAPI    bool `json:"api"`
+	HostExists  bool   `json:"host_exists"` // is the host exists?
+	FullInbox   bool   `json:"full_inbox"`  // is the email account's inbox full?
+	CatchAll    bool   `json:"catch_all"`   // does the domain have a catch-all email address?
+	Deliverable bool   `json:"deliverable"` // can send an email to the email server?
+	Disabled    bool   `json:"disabled"`    // is the email blocked or disabled by the provider?
+	Host        string `json:"host"`        // the MX host which produced the result
+	Greylisted  bool   `json:"greylisted"`  // did the server greylist the check? if so, the result is unreliable
+	TLS         bool   `json:"tls"`         // was the conversation upgraded via STARTTLS?
+	TLSFailed   bool   `json:"tls_failed"`  // was STARTTLS advertised but the upgrade failed?
+	UsingAPI    bool   `json:"api"`
+	RateLimited bool   `json:"rate_limited"` // did the provider API refuse the check due to its rate limit? if so, the result is unknown
+
+	// Tarpitted is set when the server replied, but slower than the TarpitThreshold, i.e. it is slow rather than dead.
+	// SlowestReply is the delay of its slowest reply, which helps to tune the timeouts.
+	Tarpitted    bool          `json:"tarpitted"`
+	SlowestReply time.Duration `json:"slowest_reply,omitempty"`
+
+	// GatewayDetected is set when the MX host is a mail security gateway (e.g. Proofpoint or Mimecast),
+	// which accepts any recipient and filters the mail later, so Deliverable is unreliable
+	GatewayDetected bool `json:"gateway_detected"`
+
+	// SenderBlocked is set when the server refused the check due to the reputation of the sending IP,
+	// e.g. its listing on a block list. The existence of the recipient is unknown then.
+	SenderBlocked bool `json:"sender_blocked"`
+
+	CatchAllStatus CatchAllStatus `json:"catch_all_status,omitempty"` // outcome of the catch-all probe, empty when not probed
+
+	LastStatusCode int    `json:"last_status_code,omitempty"` // the reply code of the last RCPT command, e.g. 250, 550 or 451
+	LastResponse   string `json:"last_response,omitempty"`    // the reply message of the last RCPT command
+
+	Banner     string            `json:"banner,omitempty"`     // the greeting sent by the server upon connection
+	Hello      string            `json:"hello,omitempty"`      // the greeting command accepted by the server, "EHLO" or "HELO"
+	Extensions map[string]string `json:"extensions,omitempty"` // the extensions advertised in the EHLO reply, keyed by keyword
+
+	Transcript []SMTPStep `json:"transcript,omitempty"` // the SMTP conversation, recorded when enabled by EnableSMTPTranscript
+
+	VRFY *SMTPVRFY `json:"vrfy,omitempty"` // the reply to the VRFY or EXPN command, sent when enabled by EnableVRFY
 }
 
+// CatchAllStatus is the outcome of the catch-all probe
+type CatchAllStatus string
+
+const (
+	CatchAllYes          CatchAllStatus = "yes"          // the server accepted a random address, i.e. confirmed catch-all
+	CatchAllNo           CatchAllStatus = "no"           // the server rejected a random address
+	CatchAllInconclusive CatchAllStatus = "inconclusive" // the probe could not tell (timeout, greylisting, etc.)
+	CatchAllUnknown      CatchAllStatus = "unknown"      // the probe was skipped for the domain, see SkipCatchAllDomains
+)
+
 // CheckSMTP performs an email verification on the passed domain via SMTP
 //   - the domain is the passed email domain
 //   - username is used to check the deliverability of specific email address,
 //
 // if server is catch-all server, username will not be checked
 func (v *Verifier) CheckSMTP(domain, username string) (*SMTP, error) {
+	return v.snapshot().checkSMTP(domain, username)
+}
+
+// checkSMTP performs an email verification on the passed domain via SMTP, see CheckSMTP
+func (v *Verifier) checkSMTP(domain, username string) (*SMTP, error) {
 	if !v.smtpCheckEnabled {
 		return nil, nil
 	}
 
 	domain = DomainToASCII(domain)
-	mxRecords, err := v.mxResolver.LookupMX(context.Background(), domain)
+	if apiVerifier, provider, ok := v.knownProviderAPI(domain); ok {
+		// The known provider is checked by its API without the MX lookup
+		res, err := v.checkByAPI(provider, apiVerifier, domain, username)
+		if err == nil || !v.apiFallbackEnabled {
+			return res, err
+		}
+		hosts, err := v.mxHosts(domain)
+		if err != nil {
+			return &SMTP{}, err
+		}
+		return v.probeSMTP(hosts, domain, username)
+	}
+	hosts, err := v.mxHosts(domain)
 	if err != nil {
-		return &SMTP{}, ParseSMTPError(err)
+		return &SMTP{}, err
 	}
-	if len(mxRecords) == 0 {
-		return &SMTP{}, newLookupError(0, ErrNoSuchHost, "No MX records found")
+
+	return v.checkSMTPForMX(hosts, domain, username)
+}
+
+// CheckSMTPUsernames performs the SMTP verification of several usernames at the domain, e.g. the candidates
+// of a person's address. The MX records are resolved once and the usernames are checked one after another
+// over a single connection, reset (RSET) between them. The catch-all probe is sent once, so all the usernames
+// at a catch-all domain are reported with CatchAll set rather than deliverable.
+// A username rejected by the server is reported by its result, the error is returned
+// only when the check could not be performed, along with the results so far.
+func (v *Verifier) CheckSMTPUsernames(domain string, usernames []string) (map[string]*SMTP, error) {
+	v = v.snapshot()
+	if !v.smtpCheckEnabled {
+		return nil, nil
 	}
 
-	hosts := make([]string, len(mxRecords))
-	for i, r := range mxRecords {
-		hosts[i] = r.Host
+	domain = DomainToASCII(domain)
+	hosts, err := v.mxHosts(domain)
+	if err != nil {
+		return nil, err
 	}
 
-	return v.CheckSMTPForMX(hosts, domain, username)
+	w := v.withSMTPSession()
+	defer w.smtpSession.close()
+
+	ret := make(map[string]*SMTP, len(usernames))
+	for _, username := range usernames {
+		if _, ok := ret[username]; ok {
+			continue
+		}
+		smtp, err := w.checkSMTPForMX(hosts, domain, username)
+		if smtp == nil || !smtp.HostExists {
+			return ret, err
+		}
+		ret[username] = smtp
+	}
+	return ret, nil
+}
+
+// DomainAcceptsMail checks if the domain receives mail at all, a lighter check than CheckSMTP: the MX records
+// are resolved and the conversation with the first reachable MX host ends with MAIL FROM, no recipient is probed.
+// It returns false without an error when the domain has no MX records or only the null MX (RFC 7505), the error
+// is returned when the MX lookup or the SMTP conversation fails, e.g. the server rejects the sender.
+// The check runs regardless of EnableSMTPCheck.
+func (v *Verifier) DomainAcceptsMail(domain string) (bool, error) {
+	v = v.snapshot()
+	domain = DomainToASCII(domain)
+
+	records, _, err := v.resolveMX(domain)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, ParseSMTPError(err)
+	}
+
+	hosts := make([]string, 0, len(records))
+	for _, r := range records {
+		if r.Host != "." && r.Host != "" {
+			hosts = append(hosts, r.Host)
+		}
+	}
+	if len(hosts) == 0 {
+		return false, nil
+	}
+
+	// Without the username and the catch-all probe the check ends with MAIL FROM
+	v.catchAllCheckEnabled = false
+	ret, err := v.checkSMTPForHosts(hosts, domain, "")
+	if err != nil {
+		return false, err
+	}
+	return ret.HostExists, nil
+}
+
+// mxHosts returns the MX hosts of the domain in preference order
+func (v *Verifier) mxHosts(domain string) ([]string, error) {
+	mxRecords, _, err := v.resolveMX(domain)
+	if isNotFoundDNSError(err) && v.domainNotFound(domain) {
+		return nil, newLookupError(0, ErrNoSuchHost, err.Error())
+	}
+	if err != nil && !isNotFoundDNSError(err) {
+		return nil, ParseSMTPError(err)
+	}
+
+	// The null MX (RFC 7505) declares that the domain does not accept mail
+	hosts := make([]string, 0, len(mxRecords))
+	for _, r := range mxRecords {
+		if r.Host != "." && r.Host != "" {
+			hosts = append(hosts, r.Host)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, newLookupError(0, ErrNoMXRecords, "No MX records found")
+	}
+	return hosts, nil
 }
 
 func (v *Verifier) CheckSMTPForMX(hosts []string, domain, username string) (*SMTP, error) {
+	return v.snapshot().checkSMTPForMX(hosts, domain, username)
+}
+
+// checkSMTPForMX performs an email verification via SMTP at the MX hosts of the domain
+func (v *Verifier) checkSMTPForMX(hosts []string, domain, username string) (*SMTP, error) {
 	if len(hosts) < 1 {
 		return nil, nil
 	}
 
 	// Check by api when enabled and host recognized.
-	for _, apiVerifier := range v.apiVerifiers {
+	if apiVerifier, provider, ok := v.supportedAPIVerifier(hosts); ok {
+		res, err := v.checkByAPI(provider, apiVerifier, domain, username)
+		if err == nil || !v.apiFallbackEnabled {
+			return res, err
+		}
+		// The failed API check falls back to the SMTP probe, see EnableAPIFallback
+	}
+
+	return v.probeSMTP(hosts, domain, username)
+}
+
+// supportedAPIVerifier returns the enabled API verifier supporting any of the MX hosts and its name
+func (v *Verifier) supportedAPIVerifier(hosts []string) (smtpAPIVerifier, string, bool) {
+	for provider, apiVerifier := range v.apiVerifiers {
 		for _, mx := range hosts {
 			if apiVerifier.isSupported(strings.ToLower(mx)) {
-				res, err := apiVerifier.check(domain, username)
-				if res != nil {
-					res.UsingAPI = true
-				}
+				return apiVerifier, provider, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// probeSMTP performs the SMTP check against the MX hosts, repeating the greylisted one when enabled
+func (v *Verifier) probeSMTP(hosts []string, domain, username string) (*SMTP, error) {
+	ret, err := v.checkSMTPForHosts(hosts, domain, username)
+	if ret != nil && ret.Greylisted && v.greylistRetryEnabled {
+		// Greylisting servers accept the same check when it is repeated after a delay
+		v.sleep(v.greylistRetryDelay)
+		ret, err = v.checkSMTPForHosts(hosts, domain, username)
+	}
+
+	return ret, err
+}
+
+// checkByAPI checks the address by the API verifier of the provider,
+// retrying the check refused due to the rate limit of the provider, see APIVerifierRetry
+func (v *Verifier) checkByAPI(provider string, apiVerifier smtpAPIVerifier, domain, username string) (*SMTP, error) {
+	if w, ok := apiVerifier.(clientWrapper); ok && v.hasHTTPHeaders() {
+		apiVerifier = w.wrapClient(v.httpClient)
+	}
+	check := func() (*SMTP, error) {
+		start := time.Now()
+		res, err := apiVerifier.check(domain, username)
+		dur := time.Since(start)
+		v.observer().OnAPIVerify(provider, dur, err)
+		v.debug("api verify", "provider", provider, "domain", domain, "duration", dur, "error", err)
+		return res, err
+	}
+
+	res, err := check()
+	backoff := v.apiRetryBackoff
+	for i := 1; i < v.apiRetryAttempts && isAPIRateLimitError(err); i++ {
+		v.sleep(withJitter(backoff))
+		backoff *= 2
+		res, err = check()
+	}
+
+	if isAPIRateLimitError(err) {
+		res = &SMTP{RateLimited: true}
+	}
+	if res != nil {
+		res.UsingAPI = true
+	}
+	return res, err
+}
 
-				return res, err
+// checkSMTPForHosts performs the SMTP check against the MX hosts in preference order
+func (v *Verifier) checkSMTPForHosts(hosts []string, domain, username string) (*SMTP, error) {
+	var ret *SMTP
+	var err error
+
+	// Falls back to the next MX host in preference order
+	// as long as the result of the previous one is inconclusive
+	for len(hosts) > 0 {
+		// Dial any SMTP server that will accept a connection
+		client, host, dialErr := v.smtpClientFor(hosts)
+		if dialErr != nil {
+			if ret != nil {
+				return ret, err
 			}
+			ret = &SMTP{}
+			return ret, ret.parseError(dialErr)
 		}
+
+		start := time.Now()
+		ret, err = v.checkSMTPWithClient(client, host, domain, username)
+		if client.stale {
+			// The server closed the reused connection meanwhile, the check is repeated over a new one
+			continue
+		}
+		v.observer().OnSMTPCheck(host, time.Since(start), err)
+		if !isInconclusiveSMTPError(err) {
+			return ret, err
+		}
+		hosts = hostsAfter(hosts, host)
+	}
+
+	return ret, err
+}
+
+// CheckSMTPForMXWithRetry is like CheckSMTPForMX, but retries the whole SMTP conversation up to retries times
+// while the server answers with a transient error (ErrTryAgainLater, ErrMailboxBusy, ErrExceededMessagingLimits or ErrGreylisted).
+// The delay between attempts starts at backoff and doubles after each attempt.
+// The last result and error are returned once all the retries are exhausted.
+func (v *Verifier) CheckSMTPForMXWithRetry(hosts []string, domain, username string, retries int, backoff time.Duration) (*SMTP, error) {
+	v = v.snapshot()
+	ret, err := v.checkSMTPForMX(hosts, domain, username)
+	for i := 0; i < retries && isRetryableSMTPError(err); i++ {
+		v.sleep(backoff)
+		backoff *= 2
+		ret, err = v.checkSMTPForMX(hosts, domain, username)
 	}
+	return ret, err
+}
 
-	var ret SMTP
+// checkSMTPWithClient performs the SMTP conversation over an established connection to the MX host
+func (v *Verifier) checkSMTPWithClient(client *smtpClient, host, domain, username string) (*SMTP, error) {
+	ret := SMTP{Host: host, GatewayDetected: v.isGatewayMX(host)}
 	var err error
 	email := fmt.Sprintf("%s@%s", username, domain)
 
-	// Dial any SMTP server that will accept a connection
-	client, _, err := newSMTPClient(hosts, v.proxyURI, v.dialerProvider)
-	if err != nil {
-		return &ret, ParseSMTPError(err)
+	// Defer quit the SMTP connection, or keep it for the next check
+	defer v.releaseSMTPClient(client, host)
+
+	// The conversation must not outlast the budget of Verify, the connection kept for the next check
+	// is released from the deadline unless it expired, so the connection is closed right away
+	if deadline, ok := v.context().Deadline(); ok {
+		_ = client.transcript.SetDeadline(deadline)
+		defer func() {
+			if !v.budgetExceeded() {
+				_ = client.transcript.SetDeadline(time.Time{})
+			}
+		}()
 	}
 
-	// Defer quit the SMTP connection
-	defer client.Quit()
+	if v.smtpTranscriptEnabled {
+		recorder := newSMTPRecorder(client.transcript.recorded(), client.transcript.greetingDelay())
+		client.record(recorder)
+		defer func() {
+			client.record(nil)
+			ret.Transcript = recorder.steps
+		}()
+	}
 
-	// Sets the HELO/EHLO hostname
-	if err = client.Hello(v.helloName); err != nil {
-		return &ret, ParseSMTPError(err)
+	reused := client.greeting != nil
+	if reused {
+		// The reset of the previous transaction is not timed as a part of the conversation
+		client.transcript.takeSlowestReply()
+	}
+	defer v.timeReplies(client, &ret)
+	if reused {
+		ret.Banner, ret.Extensions, ret.TLS = client.greeting.Banner, client.greeting.Extensions, client.greeting.TLS
+		ret.Hello = client.greeting.Hello
+	} else if err = v.greetSMTP(client, host, &ret); err != nil {
+		return &ret, ret.parseError(err)
 	}
 
 	// Sets the from email
-	if err = client.Mail(v.fromEmail); err != nil {
-		return &ret, ParseSMTPError(err)
+	if err = client.Mail(v.mailFrom(domain)); err != nil {
+		client.stale = reused && isClosedSMTPConnection(err)
+		return &ret, ret.parseError(err)
 	}
 
 	// Host exists if we've successfully formed a connection
 	ret.HostExists = true
 
-	if v.catchAllCheckEnabled && !v.IsFreeDomain(domain) {
-		ret.CatchAll = true
-
-		// Checks the deliver ability of a randomly generated address in
-		// order to verify the existence of a catch-all and etc.
-		randomEmail := GenerateRandomEmail(domain)
-		if err = client.Rcpt(randomEmail); err != nil {
-			if e := ParseSMTPError(err); e != nil {
-				switch e.Message {
-				case ErrFullInbox:
-					ret.FullInbox = true
-				case ErrNotAllowed:
-					ret.Disabled = true
-
-				// If The client typically receives a `550 5.1.1` code as a reply to RCPT TO command,
-				// In most cases, this is because the recipient address does not exist.
-				case ErrServerUnavailable:
-					fallthrough
-				default:
-					ret.CatchAll = false
-				}
+	probeCatchAll := v.catchAllCheckEnabled && !v.isFreeDomain(domain)
+	if probeCatchAll && v.skipCatchAll(domain) {
+		ret.CatchAllStatus = CatchAllUnknown
+		probeCatchAll = false
+	}
+	if p, ok := v.knownProvider(domain); ok && p.CatchAll != "" && v.catchAllCheckEnabled {
+		// The behavior of the known provider is not probed
+		ret.CatchAllStatus, ret.CatchAll = p.CatchAll, p.CatchAll == CatchAllYes
+		probeCatchAll = false
+		if ret.CatchAll {
+			return &ret, nil
+		}
+	}
+
+	if probeCatchAll {
+		if probe, ok := client.catchAll[domain]; ok {
+			// The domain has been probed over the reused connection already
+			ret.CatchAllStatus, ret.LastStatusCode, ret.LastResponse = probe.status, probe.code, probe.response
+		} else if probe, ok := v.domainCache.catchAll(domain); ok {
+			// The domain has been probed by a previous check, see EnableDomainCache
+			ret.CatchAllStatus, ret.LastStatusCode, ret.LastResponse = probe.status, probe.code, probe.response
+		} else {
+			v.probeCatchAll(client, domain, &ret)
+			if v.smtpSession != nil {
+				client.rememberCatchAll(domain, &ret)
 			}
+			v.domainCache.setCatchAll(domain, &ret)
 		}
+		ret.CatchAll = ret.CatchAllStatus == CatchAllYes
 
 		// If the email server is a catch-all email server,
 		// no need to calibrate deliverable on a specific user
@@ -133,28 +419,202 @@ func (v *Verifier) CheckSMTPForMX(hosts []string, domain, username string) (*SMT
 		return &ret, nil
 	}
 
-	if err = client.Rcpt(email); err != nil {
-		err = ParseSMTPError(err)
-	} else {
+	// The UTF-8 username can be sent only to the server supporting SMTPUTF8 (RFC 6531),
+	// which gets the SMTPUTF8 parameter of MAIL FROM already
+	if ok, _ := client.Extension("SMTPUTF8"); !ok && !isASCII(username) {
+		return &ret, newLookupError(0, ErrSMTPUTF8Unsupported, fmt.Sprintf("%s does not advertise SMTPUTF8", host))
+	}
+
+	if v.vrfyEnabled && v.vrfy(client, host, email, &ret) {
 		ret.Deliverable = true
+		return &ret, nil
+	}
+
+	ret.LastStatusCode, ret.LastResponse, err = v.rcpt(client, host, email)
+	if err != nil {
+		e := ret.parseError(err)
+		if e != nil && e.Message == ErrGreylisted {
+			ret.Greylisted = true
+		}
+		return &ret, e
 	}
+	ret.Deliverable = true
 
-	return &ret, err
+	return &ret, nil
 }
 
-// newSMTPClient generates a new available SMTP client
-func newSMTPClient(hosts []string, proxyURI string, dp DialerProvider) (*smtp.Client, string, error) {
-	var errs []error
-	for _, h := range hosts {
-		addr := h + smtpPort
+// timeReplies notes the delay of the slowest reply of the conversation, flagging the tarpitting server
+func (v *Verifier) timeReplies(client *smtpClient, ret *SMTP) {
+	ret.SlowestReply = client.transcript.takeSlowestReply()
+	ret.Tarpitted = ret.SlowestReply >= v.tarpitThreshold
+}
 
-		c, err := dialSMTP(addr, proxyURI, dp)
-		if err != nil {
-			errs = append(errs, err)
+// rcpt issues the RCPT command for the address to the MX host and logs the reply
+func (v *Verifier) rcpt(client *smtpClient, host, to string) (int, string, error) {
+	code, msg, err := client.rcpt(to)
+	v.debug("smtp rcpt", "host", host, "domain", to[strings.LastIndex(to, "@")+1:], "status", code, "response", msg, "error", err)
+	return code, msg, err
+}
+
+// probeCatchAll checks the deliverability of randomly generated addresses at the domain
+// to tell whether the server is a catch-all one
+func (v *Verifier) probeCatchAll(client *smtpClient, domain string, ret *SMTP) {
+	ret.CatchAllStatus = CatchAllYes
+
+	// Checks the deliver ability of randomly generated addresses in
+	// order to verify the existence of a catch-all and etc.,
+	// the server is a catch-all one only when it accepts all of them
+	for i := 0; i < v.catchAllProbes && ret.CatchAllStatus == CatchAllYes; i++ {
+		var err error
+		randomEmail := v.randomEmail(domain)
+		ret.LastStatusCode, ret.LastResponse, err = v.rcpt(client, ret.Host, randomEmail)
+		if err == nil {
 			continue
 		}
+		if e := ret.parseError(err); e != nil {
+			switch {
+			case e.Message == ErrFullInbox:
+				ret.FullInbox = true
+				ret.CatchAllStatus = CatchAllInconclusive
+			case e.Message == ErrNotAllowed:
+				ret.Disabled = true
+				ret.CatchAllStatus = CatchAllInconclusive
+			case e.Message == ErrGreylisted:
+				ret.Greylisted = true
+				ret.CatchAllStatus = CatchAllInconclusive
+			case e.Message == ErrBlocked:
+				ret.CatchAllStatus = CatchAllInconclusive
+
+			// If The client typically receives a `550 5.1.1` code as a reply to RCPT TO command,
+			// In most cases, this is because the recipient address does not exist.
+			case e.Message == ErrServerUnavailable:
+				ret.CatchAllStatus = CatchAllNo
+			case isInconclusiveSMTPError(e):
+				ret.CatchAllStatus = CatchAllInconclusive
+			default:
+				ret.CatchAllStatus = CatchAllNo
+			}
+		}
+	}
+}
+
+// greetSMTP sends the EHLO command over a new connection and upgrades it via STARTTLS when enabled
+func (v *Verifier) greetSMTP(client *smtpClient, host string, ret *SMTP) error {
+	// Sets the HELO/EHLO hostname, smtp.Client falls back to HELO whenever EHLO fails
+	err := client.Hello(v.helloName)
+	transcript := client.transcript.stop()
+	ret.Banner, ret.Extensions = parseSMTPGreeting(transcript)
+	ret.Hello = "EHLO"
+	if ehloErr := parseEHLOError(transcript); ehloErr != nil {
+		// Only the servers which do not know EHLO are greeted by HELO
+		if !v.heloFallbackEnabled || ehloErr.Code != 500 && ehloErr.Code != 502 {
+			return ehloErr
+		}
+		ret.Hello = "HELO"
+	}
+	if err != nil {
+		return err
+	}
+
+	// Upgrades the connection when the server supports it,
+	// a failed upgrade is reported instead of continuing in plaintext
+	if v.smtpTLSEnabled {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			err = client.StartTLS(v.tlsConfigForHost(host))
+			if client.recorder != nil {
+				// The upgraded connection is read and written via a new textproto.Conn
+				client.tap()
+			}
+			if err != nil {
+				ret.TLSFailed = true
+				return err
+			}
+			ret.TLS = true
+		}
+	}
+
+	client.greeting = &SMTP{Banner: ret.Banner, Extensions: ret.Extensions, TLS: ret.TLS, Hello: ret.Hello}
+	return nil
+}
+
+// parseError parses the error of the SMTP conversation, noting the refusal of the sender in the result
+func (s *SMTP) parseError(err error) *LookupError {
+	e := ParseSMTPError(err)
+	if e != nil && e.Message == ErrBlocked {
+		s.SenderBlocked = true
+	}
+	return e
+}
+
+// isInconclusiveSMTPError reports whether the error is a temporary failure (e.g. greylisting),
+// for which another MX host could give a definite answer
+func isInconclusiveSMTPError(err error) bool {
+	var e *LookupError
+	if !errors.As(err, &e) || e == nil {
+		return false
+	}
+	return e.Message == ErrTimeout || (e.Code >= 400 && e.Code < 500)
+}
+
+// isRetryableSMTPError reports whether the server asked to retry the check later
+func isRetryableSMTPError(err error) bool {
+	var e *LookupError
+	if !errors.As(err, &e) || e == nil {
+		return false
+	}
+	switch e.Message {
+	case ErrTryAgainLater, ErrMailboxBusy, ErrExceededMessagingLimits, ErrGreylisted:
+		return true
+	default:
+		return false
+	}
+}
+
+// hostsAfter returns the hosts following the passed one
+func hostsAfter(hosts []string, host string) []string {
+	for i, h := range hosts {
+		if h == host {
+			return hosts[i+1:]
+		}
+	}
+	return nil
+}
+
+// tlsConfigForHost returns the STARTTLS configuration for the passed MX host
+func (v *Verifier) tlsConfigForHost(host string) *tls.Config {
+	serverName := strings.TrimSuffix(host, ".")
+	if v.smtpTLSConfig == nil {
+		return &tls.Config{ServerName: serverName}
+	}
+
+	cfg := v.smtpTLSConfig.Clone()
+	if cfg.ServerName == "" {
+		cfg.ServerName = serverName
+	}
+	return cfg
+}
+
+// newSMTPClient generates a new available SMTP client, dialing the hosts in preference order
+// by batches of mxDialParallelism hosts dialed concurrently
+func (v *Verifier) newSMTPClient(hosts []string) (*smtpClient, string, error) {
+	n := v.mxDialParallelism
+	if n < 1 {
+		n = 1
+	}
+
+	var errs []error
+	for len(hosts) > 0 {
+		batch := hosts
+		if len(batch) > n {
+			batch = hosts[:n]
+		}
+		hosts = hosts[len(batch):]
 
-		return c, h, nil
+		c, h, batchErrs := v.dialSMTPHosts(batch)
+		if c != nil {
+			return c, h, nil
+		}
+		errs = append(errs, batchErrs...)
 	}
 
 	if len(errs) > 0 {
@@ -164,21 +624,109 @@ func newSMTPClient(hosts []string, proxyURI string, dp DialerProvider) (*smtp.Cl
 	return nil, "", errors.New("Unexpected response dialing SMTP server")
 }
 
+// dialSMTPHosts dials the hosts concurrently and returns the first connection established,
+// the slower ones are closed once established. The errors are returned in the order of the hosts.
+func (v *Verifier) dialSMTPHosts(hosts []string) (*smtpClient, string, []error) {
+	if len(hosts) == 1 {
+		c, err := v.dialSMTPHost(hosts[0])
+		if err != nil {
+			return nil, "", []error{err}
+		}
+		return c, hosts[0], nil
+	}
+
+	type dialed struct {
+		i      int
+		client *smtpClient
+		err    error
+	}
+	ch := make(chan dialed, len(hosts))
+	for i, h := range hosts {
+		go func(i int, h string) {
+			c, err := v.dialSMTPHost(h)
+			ch <- dialed{i, c, err}
+		}(i, h)
+	}
+
+	errs := make([]error, len(hosts))
+	for pending := len(hosts); pending > 0; pending-- {
+		d := <-ch
+		if d.err != nil {
+			errs[d.i] = d.err
+			continue
+		}
+
+		go func(pending int) {
+			for ; pending > 0; pending-- {
+				if d := <-ch; d.client != nil {
+					_ = d.client.Close()
+				}
+			}
+		}(pending - 1)
+		return d.client, hosts[d.i], nil
+	}
+	return nil, "", errs
+}
+
+// dialSMTPHost waits for the rate limit of the host and dials it
+func (v *Verifier) dialSMTPHost(host string) (*smtpClient, error) {
+	return v.dialSMTPAddr(host, smtpAddr(host))
+}
+
+// dialSMTPAddr waits for the rate limit of the host and dials it at the address
+func (v *Verifier) dialSMTPAddr(host, addr string) (*smtpClient, error) {
+	if v.smtpRateLimiter != nil {
+		if err := v.smtpRateLimiter.wait(v.context(), host); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	c, err := v.dialSMTP(addr)
+	dur := time.Since(start)
+	v.observer().OnSMTPDial(host, dur, err)
+	v.debug("smtp dial", "host", host, "duration", dur, "error", err)
+	return c, err
+}
+
+// smtpAddr joins the MX host with the SMTP port, bracketing IPv6 literals
+func smtpAddr(host string) string {
+	return hostPort(host, smtpPort)
+}
+
+// hostPort joins the host with the port, bracketing IPv6 literals
+func hostPort(host, port string) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return net.JoinHostPort(host, port)
+}
+
 // dialSMTP is a timeout wrapper for smtp.Dial. It attempts to dial an
-// SMTP server (socks5 proxy supported) and fails with a timeout if timeout is reached while
+// SMTP server (proxies supported) and fails with a timeout if timeout is reached while
 // attempting to establish a new connection
-func dialSMTP(addr, proxyURI string, dp DialerProvider) (*smtp.Client, error) {
+func (v *Verifier) dialSMTP(addr string) (*smtpClient, error) {
 	// Channel holding the new smtp.Client or error
 	ch := make(chan interface{}, 1)
 
+	network := v.dialNetwork
 	dial := func() (net.Conn, error) {
-		return net.Dial("tcp", addr)
+		return net.Dial(network, addr)
 	}
-	if dp != nil {
-		dial = dp.MakeDial("tcp", addr)
-	} else if len(proxyURI) > 0 {
+	if v.dialerProvider != nil {
+		dial = v.dialerProvider.MakeDial(network, addr)
+	} else if v.proxyPool != nil {
 		dial = func() (net.Conn, error) {
-			return establishProxyConnection(addr, proxyURI)
+			proxyURI := v.proxyPool.pick()
+			conn, err := v.establishProxyConnection(addr, proxyURI)
+			if err != nil {
+				v.proxyPool.markFailed(proxyURI)
+				return nil, err
+			}
+			v.proxyPool.markOK(proxyURI)
+			return conn, nil
+		}
+	} else if len(v.proxyURI) > 0 {
+		dial = func() (net.Conn, error) {
+			return v.establishProxyConnection(addr, v.proxyURI)
 		}
 	}
 
@@ -194,51 +742,132 @@ func dialSMTP(addr, proxyURI string, dp DialerProvider) (*smtp.Client, error) {
 		}
 
 		host, _, _ := net.SplitHostPort(addr)
-		client, err := smtp.NewClient(conn, host)
+		transcript := newTranscriptConn(conn)
+		client, err := smtp.NewClient(transcript, host)
 		if err != nil {
 			ch <- err
 			return
 		}
-		ch <- client
+		ch <- &smtpClient{Client: client, transcript: transcript}
 	}()
 
 	// Retrieve the smtp client from our client channel or timeout
 	select {
 	case res := <-ch:
 		switch r := res.(type) {
-		case *smtp.Client:
+		case *smtpClient:
 			return r, nil
 		case error:
 			return nil, r
 		default:
 			return nil, errors.New("Unexpected response dialing SMTP server")
 		}
-	case <-time.After(smtpTimeout):
+	case <-time.After(v.connectTimeout):
+		return nil, errors.New("Timeout connecting to mail-exchanger")
+	case <-v.context().Done():
 		return nil, errors.New("Timeout connecting to mail-exchanger")
 	}
 }
 
-// GenerateRandomEmail generates a random email address using the domain passed. Used
-// primarily for checking the existence of a catch-all address
-func GenerateRandomEmail(domain string) string {
-	r := make([]byte, 32)
-	for i := 0; i < 32; i++ {
-		r[i] = alphanumeric[rand.Intn(len(alphanumeric))]
+// establishProxyConnection connects to the address via proxy protocol, the proxy is dialed
+// on the network of the verifier within its connect timeout and the budget of the running Verify
+func (v *Verifier) establishProxyConnection(addr, proxyURI string) (net.Conn, error) {
+	u, err := url.Parse(proxyURI)
+	if err != nil {
+		return nil, err
 	}
-	return fmt.Sprintf("%s@%s", string(r), domain)
-
+	forward := &net.Dialer{Timeout: v.connectTimeout}
+	switch u.Scheme {
+	case "http", "https":
+		return v.establishHTTPProxyConnection(forward, addr, u)
+	}
+	dialer, err := proxy.FromURL(u, forward)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := dialer.(proxy.ContextDialer); ok {
+		return d.DialContext(v.context(), v.dialNetwork, addr)
+	}
+	return dialer.Dial(v.dialNetwork, addr)
 }
 
-// establishProxyConnection connects to the address on the named network address
-// via proxy protocol
-func establishProxyConnection(addr, proxyURI string) (net.Conn, error) {
-	u, err := url.Parse(proxyURI)
+// establishHTTPProxyConnection tunnels a connection to the address through
+// an HTTP(S) proxy using the CONNECT method, with Basic auth from the URL userinfo
+func (v *Verifier) establishHTTPProxyConnection(dialer *net.Dialer, addr string, u *url.URL) (net.Conn, error) {
+	proxyAddr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	ctx := v.context()
+	conn, err := dialer.DialContext(ctx, v.dialNetwork, proxyAddr)
 	if err != nil {
 		return nil, err
 	}
-	dialer, err := proxy.FromURL(u, nil)
+	// The TLS handshake and the CONNECT exchange are bounded by the connect timeout as well
+	deadline := time.Now().Add(v.connectTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err = req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
 	if err != nil {
+		_ = conn.Close()
 		return nil, err
 	}
-	return dialer.Dial("tcp", addr)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT to %s failed: %s", addr, resp.Status)
+	}
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	// The server may have already sent its greeting, which is buffered by now
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+// bufferedConn is a connection whose reads are served from the buffered reader first
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
 }
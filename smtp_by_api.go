@@ -1,8 +1,16 @@
 package emailverifier
 
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
 const (
-	GMAIL = "gmail"
-	YAHOO = "yahoo"
+	GMAIL   = "gmail"
+	YAHOO   = "yahoo"
+	OUTLOOK = "outlook"
 )
 
 type smtpAPIVerifier interface {
@@ -12,6 +20,28 @@ type smtpAPIVerifier interface {
 	check(domain, username string) (*SMTP, error)
 }
 
+// defaultClientProvider provides http.DefaultClient for every host
+type defaultClientProvider struct{}
+
+func (defaultClientProvider) MakeClient(string) (*http.Client, error) {
+	return http.DefaultClient, nil
+}
+
+// APIRateLimitError is returned when the provider API refuses the check due to its rate limit
 type APIRateLimitError struct {
 	error
 }
+
+// isAPIRateLimitError reports whether the API verifier failed due to the rate limit of the provider
+func isAPIRateLimitError(err error) bool {
+	var e *APIRateLimitError
+	return errors.As(err, &e)
+}
+
+// withJitter adds up to half of the backoff to it, so the concurrent checks refused at once do not retry at once
+func withJitter(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return backoff
+	}
+	return backoff + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
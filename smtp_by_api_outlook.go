@@ -0,0 +1,94 @@
+package emailverifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	outlookCredentialTypeAPI = "https://login.live.com/GetCredentialType.srf"
+)
+
+// Check outlook/hotmail/live email exists by the credential type lookup of the Microsoft login page.
+// The endpoint is throttled per client IP, a throttled response is reported as APIRateLimitError.
+// See https://login.live.com
+func newOutlookAPIVerifier(client *http.Client) smtpAPIVerifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return outlook{
+		client: client,
+	}
+}
+
+type outlook struct {
+	client *http.Client
+}
+
+type outlookCredentialTypeResp struct {
+	IfExistsResult int `json:"IfExistsResult"`
+	ThrottleStatus int `json:"ThrottleStatus"`
+}
+
+func (o outlook) isSupported(host string) bool {
+	// Microsoft consumer domains (outlook.com, hotmail.com, live.com, ...) are served by these MX hosts
+	return strings.HasSuffix(host, ".olc.protection.outlook.com.")
+}
+
+func (o outlook) check(domain, username string) (*SMTP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	data, err := json.Marshal(struct {
+		Username string `json:"username"`
+	}{
+		Username: fmt.Sprintf("%s@%s", username, domain),
+	})
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, outlookCredentialTypeAPI, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Content-Type", "application/json; charset=UTF-8")
+	request.Header.Add("User-Agent", USER_AGENT)
+
+	resp, err := o.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("outlook check by api, status_code: %d", resp.StatusCode)
+	}
+	var res outlookCredentialTypeResp
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("outlook check by api, decode response: %w", err)
+	}
+	if res.ThrottleStatus == 1 {
+		return nil, &APIRateLimitError{errors.New("outlook check by api, throttled")}
+	}
+
+	return &SMTP{
+		HostExists:  true,
+		Deliverable: outlookAccountExists(res.IfExistsResult),
+	}, nil
+}
+
+// outlookAccountExists interprets IfExistsResult, 1 means that no account exists,
+// 0, 5 and 6 mean that a (personal and/or work) account exists
+func outlookAccountExists(ifExistsResult int) bool {
+	switch ifExistsResult {
+	case 0, 5, 6:
+		return true
+	default:
+		return false
+	}
+}
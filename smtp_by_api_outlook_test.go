@@ -0,0 +1,83 @@
+package emailverifier
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestOutlookCheckByAPI(t *testing.T) {
+	outlookAPIVerifier := newOutlookAPIVerifier(nil)
+
+	t.Run("email exists", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://login.live.com").
+			Post("/GetCredentialType.srf").
+			BodyString(`"username":"someone@outlook.com"`).
+			Reply(http.StatusOK).
+			JSON(map[string]int{"IfExistsResult": 0})
+
+		res, err := outlookAPIVerifier.check("outlook.com", "someone")
+		assert.NoError(t, err)
+		assert.Equal(t, true, res.HostExists)
+		assert.Equal(t, true, res.Deliverable)
+	})
+	t.Run("email not exists", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://login.live.com").
+			Post("/GetCredentialType.srf").
+			Reply(http.StatusOK).
+			JSON(map[string]int{"IfExistsResult": 1})
+
+		res, err := outlookAPIVerifier.check("hotmail.com", "someone")
+		assert.NoError(t, err)
+		assert.Equal(t, true, res.HostExists)
+		assert.Equal(t, false, res.Deliverable)
+	})
+	t.Run("throttled", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://login.live.com").
+			Post("/GetCredentialType.srf").
+			Reply(http.StatusOK).
+			JSON(map[string]int{"IfExistsResult": 1, "ThrottleStatus": 1})
+
+		_, err := outlookAPIVerifier.check("live.com", "someone")
+		assert.IsType(t, &APIRateLimitError{}, err)
+	})
+	t.Run("error page", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://login.live.com").
+			Post("/GetCredentialType.srf").
+			Reply(http.StatusServiceUnavailable).
+			BodyString("<html>Service Unavailable</html>")
+
+		res, err := outlookAPIVerifier.check("live.com", "someone")
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		var rateLimitErr *APIRateLimitError
+		assert.False(t, errors.As(err, &rateLimitErr))
+	})
+	t.Run("malformed response", func(tt *testing.T) {
+		defer gock.Off()
+		gock.New("https://login.live.com").
+			Post("/GetCredentialType.srf").
+			Reply(http.StatusOK).
+			BodyString("<html></html>")
+
+		res, err := outlookAPIVerifier.check("live.com", "someone")
+		assert.Error(t, err)
+		assert.Nil(t, res)
+		var rateLimitErr *APIRateLimitError
+		assert.False(t, errors.As(err, &rateLimitErr))
+	})
+}
+
+func TestOutlookIsSupported(t *testing.T) {
+	outlookAPIVerifier := newOutlookAPIVerifier(nil)
+	assert.True(t, outlookAPIVerifier.isSupported("hotmail-com.olc.protection.outlook.com."))
+	assert.True(t, outlookAPIVerifier.isSupported("outlook-com.olc.protection.outlook.com."))
+	assert.False(t, outlookAPIVerifier.isSupported("aftership-com.mail.protection.outlook.com."))
+}
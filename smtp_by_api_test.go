@@ -0,0 +1,154 @@
+package emailverifier
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rateLimitedAPIVerifier refuses the first limited checks due to the rate limit
+type rateLimitedAPIVerifier struct {
+	limited int
+	checks  int
+}
+
+func (*rateLimitedAPIVerifier) isSupported(host string) bool { return true }
+
+func (a *rateLimitedAPIVerifier) check(domain, username string) (*SMTP, error) {
+	a.checks++
+	if a.checks <= a.limited {
+		return nil, &APIRateLimitError{errors.New("throttled")}
+	}
+	return &SMTP{HostExists: true, Deliverable: true}, nil
+}
+
+func TestCheckSMTPForMXOK_APIRateLimitRetry(t *testing.T) {
+	apiVerifier := &rateLimitedAPIVerifier{limited: 2}
+	verifier := NewVerifier().APIVerifierRetry(3, time.Millisecond)
+	verifier.apiVerifiers["FAKE"] = apiVerifier
+
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.test."}, "example.test", "someone")
+	assert.NoError(t, err)
+	assert.Equal(t, &SMTP{HostExists: true, Deliverable: true, UsingAPI: true}, smtp)
+	assert.Equal(t, 3, apiVerifier.checks)
+}
+
+func TestCheckSMTPForMXFailed_APIRateLimited(t *testing.T) {
+	apiVerifier := &rateLimitedAPIVerifier{limited: 3}
+	verifier := NewVerifier().APIVerifierRetry(2, time.Millisecond)
+	verifier.apiVerifiers["FAKE"] = apiVerifier
+
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.test."}, "example.test", "someone")
+	assert.True(t, isAPIRateLimitError(err))
+	assert.Equal(t, &SMTP{RateLimited: true, UsingAPI: true}, smtp)
+	assert.Equal(t, 2, apiVerifier.checks)
+}
+
+func TestCheckSMTPForMXFailed_APIRateLimitNotRetried(t *testing.T) {
+	apiVerifier := &rateLimitedAPIVerifier{limited: 1}
+	verifier := NewVerifier()
+	verifier.apiVerifiers["FAKE"] = apiVerifier
+
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.test."}, "example.test", "someone")
+	assert.Error(t, err)
+	assert.True(t, smtp.RateLimited)
+	assert.Equal(t, 1, apiVerifier.checks)
+}
+
+func TestWithJitter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), withJitter(0))
+	for i := 0; i < 100; i++ {
+		d := withJitter(time.Second)
+		assert.True(t, d >= time.Second && d <= 1500*time.Millisecond, d.String())
+	}
+}
+
+func TestCheckSMTPOK_APIFallback(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@" + domain)
+	verifier := srv.verifier(domain).EnableAPIFallback()
+	verifier.apiVerifiers["FAKE"] = &rateLimitedAPIVerifier{limited: 1}
+
+	smtp, err := verifier.CheckSMTP(domain, "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.HostExists)
+	assert.True(t, smtp.Deliverable)
+	assert.False(t, smtp.UsingAPI)
+	assert.NotEmpty(t, filterCommands(srv.received(), "RCPT"))
+}
+
+func TestCheckSMTPOK_KnownProviderAPIFallback(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@" + domain)
+	verifier := srv.verifier(domain).EnableAPIFallback().EnableKnownProviders().
+		AddKnownProviders(map[string]KnownProvider{domain: {Name: "example", API: "FAKE"}})
+	apiVerifier := &rateLimitedAPIVerifier{limited: 1}
+	verifier.apiVerifiers["FAKE"] = apiVerifier
+
+	smtp, err := verifier.CheckSMTP(domain, "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.Deliverable)
+	assert.False(t, smtp.UsingAPI)
+	// The API is not checked again by CheckSMTPForMX
+	assert.Equal(t, 1, apiVerifier.checks)
+}
+
+func TestCheckSMTPFailed_APIFallbackDisabled(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier(domain)
+	verifier.apiVerifiers["FAKE"] = &rateLimitedAPIVerifier{limited: 1}
+
+	_, err := verifier.CheckSMTP(domain, "someone")
+	assert.True(t, isAPIRateLimitError(err))
+	assert.Empty(t, srv.received())
+}
+
+// TestAPIVerifier_ConcurrentToggle toggles the API verifiers while checking, run it with -race
+func TestAPIVerifier_ConcurrentToggle(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier("example.test").DisableCatchAllCheck()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for _, name := range []string{GMAIL, OUTLOOK, YAHOO} {
+				if i%2 == 0 {
+					assert.NoError(t, verifier.EnableAPIVerifier(name, nil))
+				} else {
+					verifier.DisableAPIVerifier(name)
+				}
+			}
+		}
+	}()
+
+	var checks sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		checks.Add(1)
+		go func() {
+			defer checks.Done()
+			for j := 0; j < 10; j++ {
+				// The MX host is supported by none of the API verifiers, which are looked up nevertheless
+				ret, err := verifier.CheckSMTP("example.test", "john")
+				assert.NoError(t, err)
+				assert.False(t, ret.UsingAPI)
+			}
+		}()
+	}
+	checks.Wait()
+	close(done)
+	wg.Wait()
+}
@@ -23,6 +23,9 @@ const (
 // See https://login.yahoo.com
 // See https://login.yahoo.com/account/create
 func newYahooAPIVerifier(cp ClientProvider) smtpAPIVerifier {
+	if cp == nil {
+		cp = defaultClientProvider{}
+	}
 	return yahoo{
 		cp: cp,
 	}
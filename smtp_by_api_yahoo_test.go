@@ -44,3 +44,13 @@ func TestGetAcrumb(t *testing.T) {
 	acrumb = getAcrumb(cookies2)
 	assert.Equal(t, acrumb, "")
 }
+
+func TestEnableAPIVerifierOK_YahooWithoutClientProvider(t *testing.T) {
+	v := NewVerifier()
+	assert.NoError(t, v.EnableAPIVerifier(YAHOO, nil))
+	assert.Equal(t, yahoo{cp: defaultClientProvider{}}, v.apiVerifiers[YAHOO])
+
+	client, err := defaultClientProvider{}.MakeClient("yahoo.com")
+	assert.NoError(t, err)
+	assert.Same(t, http.DefaultClient, client)
+}
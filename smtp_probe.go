@@ -0,0 +1,78 @@
+package emailverifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProbeRecipient checks the deliverability of the recipient by a single SMTP conversation with the host,
+// i.e. connect, EHLO, MAIL FROM and RCPT TO, without any DNS lookup, API verifier or catch-all probe.
+// The port defaults to 25 and the fromEmail to the configured one, see FromEmail. The connection honors
+// the configured dialer, proxy, rate limit and STARTTLS, and the transcript is recorded when enabled.
+func (v *Verifier) ProbeRecipient(host, port, fromEmail, recipient string) (*SMTP, error) {
+	v = v.snapshot()
+	at := strings.LastIndex(recipient, "@")
+	if at < 1 || at == len(recipient)-1 {
+		return nil, fmt.Errorf("invalid recipient: %q", recipient)
+	}
+	username, domain := recipient[:at], recipient[at+1:]
+	if port == "" {
+		port = smtpPort
+	}
+	if fromEmail == "" {
+		fromEmail = v.mailFrom(domain)
+	}
+
+	client, err := v.dialSMTPAddr(host, hostPort(host, port))
+	if err != nil {
+		ret := &SMTP{}
+		return ret, ret.parseError(err)
+	}
+
+	start := time.Now()
+	ret, err := v.probeRecipientWithClient(client, host, fromEmail, username, recipient)
+	v.observer().OnSMTPCheck(host, time.Since(start), err)
+	return ret, err
+}
+
+// probeRecipientWithClient performs the SMTP conversation probing the recipient over a new connection to the host
+func (v *Verifier) probeRecipientWithClient(client *smtpClient, host, fromEmail, username, recipient string) (*SMTP, error) {
+	ret := SMTP{Host: host, GatewayDetected: v.isGatewayMX(host)}
+	defer func() { _ = client.Quit() }()
+	defer v.timeReplies(client, &ret)
+
+	if v.smtpTranscriptEnabled {
+		recorder := newSMTPRecorder(client.transcript.recorded(), client.transcript.greetingDelay())
+		client.record(recorder)
+		defer func() {
+			client.record(nil)
+			ret.Transcript = recorder.steps
+		}()
+	}
+
+	if err := v.greetSMTP(client, host, &ret); err != nil {
+		return &ret, ret.parseError(err)
+	}
+	if err := client.Mail(fromEmail); err != nil {
+		return &ret, ret.parseError(err)
+	}
+	ret.HostExists = true
+
+	if ok, _ := client.Extension("SMTPUTF8"); !ok && !isASCII(username) {
+		return &ret, newLookupError(0, ErrSMTPUTF8Unsupported, fmt.Sprintf("%s does not advertise SMTPUTF8", host))
+	}
+
+	var err error
+	ret.LastStatusCode, ret.LastResponse, err = v.rcpt(client, host, recipient)
+	if err != nil {
+		e := ret.parseError(err)
+		if e != nil && e.Message == ErrGreylisted {
+			ret.Greylisted = true
+		}
+		return &ret, e
+	}
+	ret.Deliverable = true
+
+	return &ret, nil
+}
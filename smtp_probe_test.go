@@ -0,0 +1,62 @@
+package emailverifier
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeRecipientOK(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("john@example.test")
+	host, port, _ := net.SplitHostPort(srv.ln.Addr().String())
+
+	ret, err := NewVerifier().ProbeRecipient(host, port, "probe@sender.test", "john@example.test")
+	assert.NoError(t, err)
+	assert.True(t, ret.HostExists)
+	assert.True(t, ret.Deliverable)
+	assert.Equal(t, host, ret.Host)
+	assert.Equal(t, 250, ret.LastStatusCode)
+	assert.Empty(t, ret.CatchAllStatus)
+
+	// A single conversation without the catch-all probe
+	assert.Equal(t, []string{"MAIL FROM:<probe@sender.test>"}, filterCommands(srv.received(), "MAIL"))
+	assert.Equal(t, []string{"RCPT TO:<john@example.test>"}, filterCommands(srv.received(), "RCPT"))
+}
+
+func TestProbeRecipientOK_Tarpitted(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "RCPT" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return ""
+	}
+
+	verifier := NewVerifier().EnableCustomDialer(srv.dialer()).TarpitThreshold(50 * time.Millisecond)
+	smtp, err := verifier.ProbeRecipient("mx.example.com", "", "", "someone@example.com")
+	assert.NoError(t, err)
+	assert.True(t, smtp.Tarpitted)
+}
+
+func TestProbeRecipientFailed_UserUnknown(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT()
+
+	ret, err := NewVerifier().EnableCustomDialer(srv.dialer()).ProbeRecipient("mx.example.test", "", "", "john@example.test")
+	assert.Error(t, err)
+	assert.True(t, ret.HostExists)
+	assert.False(t, ret.Deliverable)
+	assert.Equal(t, 550, ret.LastStatusCode)
+	assert.Equal(t, []string{"MAIL FROM:<user@example.org>"}, filterCommands(srv.received(), "MAIL"))
+}
+
+func TestProbeRecipientFailed_InvalidRecipient(t *testing.T) {
+	for _, recipient := range []string{"", "john", "@example.test", "john@"} {
+		ret, err := NewVerifier().ProbeRecipient("mx.example.test", "", "", recipient)
+		assert.Error(t, err, recipient)
+		assert.Nil(t, ret, recipient)
+	}
+}
@@ -0,0 +1,109 @@
+package emailverifier
+
+import (
+	"errors"
+	"net/textproto"
+)
+
+// smtpSession keeps the connection of a BatchVerify worker open after a check, so the following
+// checks against the same MX host reuse it instead of dialing the host again. It is used by a single
+// worker, i.e. sequentially.
+type smtpSession struct {
+	host   string
+	client *smtpClient
+}
+
+// take returns the open connection when it leads to one of the hosts
+func (s *smtpSession) take(hosts []string) (*smtpClient, string) {
+	if s.client == nil {
+		return nil, ""
+	}
+	for _, h := range hosts {
+		if h == s.host {
+			client := s.client
+			s.client = nil
+			return client, h
+		}
+	}
+	return nil, ""
+}
+
+// put keeps the connection open, quitting the one kept so far
+func (s *smtpSession) put(host string, client *smtpClient) {
+	s.close()
+	s.host, s.client = host, client
+}
+
+// close quits the kept connection
+func (s *smtpSession) close() {
+	if s.client != nil {
+		_ = s.client.Quit()
+		s.client = nil
+	}
+}
+
+// withSMTPSession returns a copy of the verifier reusing the SMTP connections across its checks,
+// the session has to be closed once the checks are done. Without the MX cache enabled, the copy
+// gets its own MX cache for the lifetime of the session, whose entries live at most batchMXCacheTTL
+// (or the TTL of the DNS answer), otherwise it shares the MX cache of the verifier.
+func (v *Verifier) withSMTPSession() *Verifier {
+	ret := *v
+	ret.smtpSession = &smtpSession{}
+	if ret.mxCache == nil {
+		// Resolves the MX records once per domain for both the MX and the SMTP check
+		ret.mxCache = newMXCache(batchMXCacheTTL)
+	}
+	return &ret
+}
+
+// smtpClientFor returns the connection to one of the hosts kept open by the SMTP session,
+// or dials a new one
+func (v *Verifier) smtpClientFor(hosts []string) (*smtpClient, string, error) {
+	if v.smtpSession != nil {
+		if client, host := v.smtpSession.take(hosts); client != nil {
+			return client, host, nil
+		}
+	}
+	return v.newSMTPClient(hosts)
+}
+
+// releaseSMTPClient keeps the connection open for the next check when reusing the connections
+// and the server accepts the reset of the transaction, quits it otherwise
+func (v *Verifier) releaseSMTPClient(client *smtpClient, host string) {
+	switch {
+	case client.stale:
+		_ = client.Close()
+	case v.smtpSession == nil:
+		_ = client.Quit()
+	case client.Reset() != nil:
+		_ = client.Close()
+	default:
+		v.smtpSession.put(host, client)
+	}
+}
+
+// catchAllProbe is the outcome of the catch-all probe of a domain over a reused connection
+type catchAllProbe struct {
+	status   CatchAllStatus
+	code     int
+	response string
+}
+
+// rememberCatchAll keeps the definite outcome of the catch-all probe of the domain for the following
+// checks over the connection
+func (c *smtpClient) rememberCatchAll(domain string, ret *SMTP) {
+	if ret.CatchAllStatus != CatchAllYes && ret.CatchAllStatus != CatchAllNo {
+		return
+	}
+	if c.catchAll == nil {
+		c.catchAll = make(map[string]catchAllProbe)
+	}
+	c.catchAll[domain] = catchAllProbe{status: ret.CatchAllStatus, code: ret.LastStatusCode, response: ret.LastResponse}
+}
+
+// isClosedSMTPConnection reports whether the error shows the server closed the connection,
+// i.e. it is not a reply of the server or the server is closing the transmission channel (421)
+func isClosedSMTPConnection(err error) bool {
+	var e *textproto.Error
+	return !errors.As(err, &e) || e.Code == 421
+}
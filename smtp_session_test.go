@@ -0,0 +1,93 @@
+package emailverifier
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchVerifyOK_SMTPConnectionReuse(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("a@example.test", "c@example.test")
+
+	verifier := srv.verifier("example.test").EnableSMTPConnectionReuse()
+	results, err := verifier.BatchVerify([]string{"a@example.test", "b@example.test", "c@example.test"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, reachableYes, results[0].Reachable)
+	assert.NotEmpty(t, results[1].Error)
+	assert.Equal(t, reachableYes, results[2].Reachable)
+
+	received := srv.received()
+	assert.Len(t, filterCommands(received, "EHLO"), 1)
+	assert.Len(t, filterCommands(received, "MAIL"), 3)
+	assert.Len(t, filterCommands(received, "RSET"), 3)
+	assert.Len(t, filterCommands(received, "QUIT"), 1)
+	// a single catch-all probe followed by the recipients
+	assert.Len(t, filterCommands(received, "RCPT"), 4)
+}
+
+func TestBatchVerifyOK_SMTPConnectionReusePerDomain(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("a@one.test", "b@two.test", "c@one.test")
+	resolver := &flakyResolver{mockResolver: &mockResolver{mx: map[string][]*net.MX{
+		"one.test": {{Host: "mx.one.test.", Pref: 10}},
+		"two.test": {{Host: "mx.two.test.", Pref: 10}},
+	}}}
+
+	verifier := srv.verifier().EnableMXResolver(resolver).EnableSMTPConnectionReuse()
+	emails := []string{"a@one.test", "b@two.test", "c@one.test"}
+	results, err := verifier.BatchVerify(emails, 1)
+	assert.NoError(t, err)
+	for i, r := range results {
+		assert.Equal(t, emails[i], r.Email)
+		assert.Equal(t, reachableYes, r.Reachable)
+		assert.Equal(t, CatchAllNo, r.SMTP.CatchAllStatus)
+	}
+
+	// the domains are checked one after another, each resolved and probed once
+	assert.Equal(t, 2, resolver.lookups)
+	assert.Len(t, filterCommands(srv.received(), "EHLO"), 2)
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 5)
+}
+
+func TestBatchVerifyOK_SMTPConnectionReuseDisabled(t *testing.T) {
+	srv := newMockSMTPServer(t)
+
+	verifier := srv.verifier("example.test")
+	_, err := verifier.BatchVerify([]string{"a@example.test", "b@example.test"}, 1)
+	assert.NoError(t, err)
+
+	received := srv.received()
+	assert.Len(t, filterCommands(received, "EHLO"), 2)
+	assert.Empty(t, filterCommands(received, "RSET"))
+}
+
+func TestBatchVerifyOK_SMTPConnectionReuseClosedByServer(t *testing.T) {
+	var mails int32
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		// the second check finds the kept connection timed out
+		if cmd == "MAIL" && atomic.AddInt32(&mails, 1) == 2 {
+			return "421 4.4.2 Idle timeout, closing connection"
+		}
+		return ""
+	}
+
+	verifier := srv.verifier("example.test").EnableSMTPConnectionReuse()
+	results, err := verifier.BatchVerify([]string{"a@example.test", "b@example.test"}, 1)
+	assert.NoError(t, err)
+	for _, r := range results {
+		assert.Empty(t, r.Error)
+		assert.True(t, r.SMTP.HostExists)
+	}
+	assert.Len(t, filterCommands(srv.received(), "EHLO"), 2)
+}
+
+func TestBatchGroups(t *testing.T) {
+	emails := []string{"a@one.test", "b@two.test", "c@One.test", "invalid", "d@two.test"}
+
+	assert.Equal(t, [][]int{{0}, {1}, {2}, {3}, {4}}, NewVerifier().batchGroups(emails))
+	assert.Equal(t, [][]int{{0, 2}, {1, 4}, {3}}, NewVerifier().EnableSMTPConnectionReuse().batchGroups(emails))
+}
@@ -0,0 +1,317 @@
+package emailverifier
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTranscriptSize bounds the recorded server replies
+const maxTranscriptSize = 64 << 10
+
+// smtpClient is an SMTP client keeping the transcript of the server replies
+// received before the conversation is upgraded or the envelope is sent
+type smtpClient struct {
+	*smtp.Client
+	transcript *transcriptConn
+
+	recorder *smtpRecorder   // records the conversation of the current check, nil when not recording
+	tapped   *textproto.Conn // the connection tapped for the recorder
+
+	greeting *SMTP                    // the outcome of the EHLO and STARTTLS of a reused connection, nil for a fresh one
+	stale    bool                     // whether the reused connection turned out to be closed by the server
+	catchAll map[string]catchAllProbe // the catch-all probes of the domains checked over the reused connection
+}
+
+// rcpt issues a RCPT command like smtp.Client.Rcpt, additionally returning the reply code and message
+func (c *smtpClient) rcpt(to string) (int, string, error) {
+	return c.cmd("RCPT TO:<%s>", to)
+}
+
+// cmd issues the command with the address, expecting a 25x reply, and returns the reply code and message
+func (c *smtpClient) cmd(format, addr string) (int, string, error) {
+	if strings.ContainsAny(addr, "\r\n") {
+		return 0, "", errors.New("smtp: A line must not contain CR or LF")
+	}
+	id, err := c.Text.Cmd(format, addr)
+	if err != nil {
+		return 0, "", err
+	}
+	c.Text.StartResponse(id)
+	defer c.Text.EndResponse(id)
+	return c.Text.ReadResponse(25)
+}
+
+// transcriptConn is a connection recording the data read from it until stopped,
+// and timing the replies of the server, i.e. the delay of the first data read after the data written
+type transcriptConn struct {
+	net.Conn
+
+	mu        sync.Mutex
+	recording bool
+	buf       bytes.Buffer
+
+	waiting  time.Time     // when the data awaiting the reply were written, zero when replied
+	greeted  bool          // whether the greeting has been received
+	greeting time.Duration // the delay of the greeting upon connection
+	slowest  time.Duration // the delay of the slowest reply since taken
+}
+
+// newTranscriptConn wraps the connection just established, so the delay of the greeting is timed
+func newTranscriptConn(conn net.Conn) *transcriptConn {
+	return &transcriptConn{Conn: conn, recording: true, waiting: time.Now()}
+}
+
+func (c *transcriptConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.mu.Lock()
+	if c.recording && c.buf.Len()+n <= maxTranscriptSize {
+		c.buf.Write(b[:n])
+	}
+	if n > 0 && !c.waiting.IsZero() {
+		d := time.Since(c.waiting)
+		if !c.greeted {
+			c.greeting, c.greeted = d, true
+		}
+		if d > c.slowest {
+			c.slowest = d
+		}
+		c.waiting = time.Time{}
+	}
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c *transcriptConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	if c.waiting.IsZero() {
+		c.waiting = time.Now()
+	}
+	c.mu.Unlock()
+	return c.Conn.Write(b)
+}
+
+// takeSlowestReply returns the delay of the slowest reply since the last call, i.e. of the conversation
+// of the current check
+func (c *transcriptConn) takeSlowestReply() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ret := c.slowest
+	c.slowest = 0
+	return ret
+}
+
+// greetingDelay returns the delay of the greeting upon connection
+func (c *transcriptConn) greetingDelay() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.greeting
+}
+
+// recorded returns the data recorded so far
+func (c *transcriptConn) recorded() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String()
+}
+
+// stop stops the recording and returns the recorded data
+func (c *transcriptConn) stop() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recording = false
+	ret := c.buf.String()
+	c.buf.Reset()
+	return ret
+}
+
+// SMTPStep is a command sent to the SMTP server along with the reply of the server
+type SMTPStep struct {
+	Command  string        `json:"command"`  // the command as sent, empty for the greeting upon connection
+	Code     int           `json:"code"`     // the reply code, zero when no reply was received
+	Message  string        `json:"message"`  // the reply text, the lines of a multi-line reply are joined by "\n"
+	Duration time.Duration `json:"duration"` // the delay of the reply after the command, or after the connection for the greeting
+}
+
+// smtpRecorder records the SMTP conversation of a check in steps. The data are recorded
+// above the TLS layer, but the EHLO command net/smtp repeats within StartTLS is missed.
+type smtpRecorder struct {
+	steps []SMTPStep
+	sent  time.Time // when the command of the last step was sent
+}
+
+// newSMTPRecorder creates a recorder starting with the greeting of the server and its delay,
+// the greeting is empty for a reused connection
+func newSMTPRecorder(greeting string, delay time.Duration) *smtpRecorder {
+	r := &smtpRecorder{}
+	if greeting == "" {
+		return r
+	}
+	r.steps = append(r.steps, SMTPStep{Duration: delay})
+	for _, line := range strings.Split(strings.TrimSuffix(greeting, "\n"), "\n") {
+		r.reply(line)
+	}
+	return r
+}
+
+func (r *smtpRecorder) command(line string) {
+	r.steps = append(r.steps, SMTPStep{Command: line})
+	r.sent = time.Now()
+}
+
+func (r *smtpRecorder) reply(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	if len(line) < 3 || len(r.steps) == 0 {
+		return
+	}
+	step := &r.steps[len(r.steps)-1]
+	if step.Code == 0 {
+		step.Code, _ = strconv.Atoi(line[:3])
+		if step.Command != "" {
+			step.Duration = time.Since(r.sent)
+		}
+	}
+	if len(line) > 4 {
+		if step.Message != "" {
+			step.Message += "\n"
+		}
+		step.Message += line[4:]
+	}
+}
+
+// record passes the conversation to the recorder until called with nil
+func (c *smtpClient) record(r *smtpRecorder) {
+	c.recorder = r
+	if r != nil {
+		c.tap()
+	}
+}
+
+// tap passes the commands written and the replies read via the textproto.Conn to the recorder,
+// it has to be repeated whenever the client replaces the connection, i.e. by StartTLS
+func (c *smtpClient) tap() {
+	if c.Text == nil || c.Text == c.tapped {
+		return
+	}
+	c.tapped = c.Text
+	c.Text.R = bufio.NewReader(io.TeeReader(c.Text.R, &lineWriter{fn: func(line string) {
+		if c.recorder != nil {
+			c.recorder.reply(line)
+		}
+	}}))
+	c.Text.W = bufio.NewWriter(io.MultiWriter(flushWriter{c.Text.W}, &lineWriter{fn: func(line string) {
+		if c.recorder != nil {
+			c.recorder.command(line)
+		}
+	}}))
+}
+
+// lineWriter passes every complete line written to it to fn, without the line ending
+type lineWriter struct {
+	buf bytes.Buffer
+	fn  func(string)
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		w.fn(strings.TrimRight(string(w.buf.Next(i+1)), "\r\n"))
+	}
+}
+
+// flushWriter writes through the buffered writer
+type flushWriter struct {
+	w *bufio.Writer
+}
+
+func (w flushWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	if err != nil {
+		return n, err
+	}
+	return n, w.w.Flush()
+}
+
+// parseSMTPGreeting parses the server replies to the connection and to the EHLO command,
+// returning the banner and the advertised extensions keyed by the upper-cased keyword
+func parseSMTPGreeting(transcript string) (string, map[string]string) {
+	replies := splitSMTPReplies(transcript)
+
+	var banner string
+	var extensions map[string]string
+	if len(replies) > 0 && strings.HasPrefix(replies[0][0], "220 ") {
+		texts := make([]string, len(replies[0]))
+		for i, l := range replies[0] {
+			texts[i] = l[4:]
+		}
+		banner = strings.Join(texts, "\n")
+	}
+	if len(replies) > 1 && strings.HasPrefix(replies[1][0], "250 ") {
+		// the first line holds the server name, the others the extensions
+		for _, l := range replies[1][1:] {
+			keyword, params := l[4:], ""
+			if i := strings.IndexByte(keyword, ' '); i >= 0 {
+				keyword, params = keyword[:i], keyword[i+1:]
+			}
+			if extensions == nil {
+				extensions = map[string]string{}
+			}
+			extensions[strings.ToUpper(keyword)] = params
+		}
+	}
+	return banner, extensions
+}
+
+// parseEHLOError returns the rejection of the EHLO command in the transcript of the greeting,
+// nil when the server accepted it or did not reply at all
+func parseEHLOError(transcript string) *textproto.Error {
+	replies := splitSMTPReplies(transcript)
+	if len(replies) < 2 || strings.HasPrefix(replies[1][0], "250 ") {
+		return nil
+	}
+	code, err := strconv.Atoi(replies[1][0][:3])
+	if err != nil {
+		return nil
+	}
+	texts := make([]string, len(replies[1]))
+	for i, l := range replies[1] {
+		texts[i] = l[4:]
+	}
+	return &textproto.Error{Code: code, Msg: strings.Join(texts, "\n")}
+}
+
+// splitSMTPReplies splits the transcript of the server replies into the replies, each holding
+// its lines normalized to the code followed by a space and the text
+func splitSMTPReplies(transcript string) [][]string {
+	var replies [][]string
+	var reply []string
+	for _, line := range strings.Split(transcript, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) < 3 {
+			continue
+		}
+		text := ""
+		if len(line) > 4 {
+			text = line[4:]
+		}
+		reply = append(reply, line[:3]+" "+text)
+		// the last line of a reply has no hyphen after the code
+		if len(line) == 3 || line[3] != '-' {
+			replies = append(replies, reply)
+			reply = nil
+		}
+	}
+	return replies
+}
@@ -0,0 +1,210 @@
+package emailverifier
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSMTPGreeting(t *testing.T) {
+	banner, extensions := parseSMTPGreeting("220-mx.example.com ESMTP\r\n220 No UCE\r\n" +
+		"250-mx.example.com Hello\r\n250-SIZE 35882577\r\n250-8BITMIME\r\n250-starttls\r\n250 AUTH LOGIN PLAIN\r\n")
+
+	assert.Equal(t, "mx.example.com ESMTP\nNo UCE", banner)
+	assert.Equal(t, map[string]string{
+		"SIZE":     "35882577",
+		"8BITMIME": "",
+		"STARTTLS": "",
+		"AUTH":     "LOGIN PLAIN",
+	}, extensions)
+}
+
+func TestParseSMTPGreeting_NoExtensions(t *testing.T) {
+	banner, extensions := parseSMTPGreeting("220 mx.example.com ESMTP\r\n250 mx.example.com\r\n")
+
+	assert.Equal(t, "mx.example.com ESMTP", banner)
+	assert.Nil(t, extensions)
+}
+
+func TestParseSMTPGreeting_EHLORejected(t *testing.T) {
+	banner, extensions := parseSMTPGreeting("220 mx.example.com\r\n502 Command not implemented\r\n250 mx.example.com\r\n")
+
+	assert.Equal(t, "mx.example.com", banner)
+	assert.Nil(t, extensions)
+}
+
+func TestParseSMTPGreeting_Empty(t *testing.T) {
+	banner, extensions := parseSMTPGreeting("")
+
+	assert.Empty(t, banner)
+	assert.Nil(t, extensions)
+}
+
+func TestParseEHLOError(t *testing.T) {
+	err := parseEHLOError("220 mx.example.com\r\n502-Command not implemented\r\n502 Use HELO\r\n250 mx.example.com\r\n")
+	assert.Equal(t, &textproto.Error{Code: 502, Msg: "Command not implemented\nUse HELO"}, err)
+
+	assert.Nil(t, parseEHLOError("220 mx.example.com\r\n250-mx.example.com\r\n250 SIZE 1000\r\n"))
+	assert.Nil(t, parseEHLOError("220 mx.example.com\r\n"))
+}
+
+func TestCheckSMTPForMXOK_HELOFallback(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "EHLO" {
+			return "502 Command not implemented"
+		}
+		return ""
+	}
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer()).DisableCatchAllCheck()
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.Deliverable)
+	assert.Equal(t, "HELO", smtp.Hello)
+	assert.Len(t, filterCommands(srv.received(), "HELO"), 1)
+
+	_, err = verifier.DisableHELOFallback().CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	var le *LookupError
+	assert.True(t, errors.As(err, &le))
+	assert.Equal(t, 502, le.Code)
+}
+
+func TestCheckSMTPForMXFailed_EHLORefused(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "EHLO" {
+			return "554 5.7.1 Access denied"
+		}
+		return ""
+	}
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer()).DisableCatchAllCheck()
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	var le *LookupError
+	assert.True(t, errors.As(err, &le))
+	assert.Equal(t, 554, le.Code)
+	assert.False(t, smtp.HostExists)
+	assert.Empty(t, filterCommands(srv.received(), "MAIL"))
+}
+
+func TestCheckSMTPForMXOK_BannerAndExtensions(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.extensions = []string{"SIZE 1024", "8BITMIME"}
+	srv.reply = rejectRandomRCPT("someone@example.com")
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer())
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.NoError(t, err)
+	assert.Equal(t, "mock.local ESMTP ready", smtp.Banner)
+	assert.Equal(t, map[string]string{"SIZE": "1024", "8BITMIME": ""}, smtp.Extensions)
+	assert.Equal(t, "EHLO", smtp.Hello)
+}
+
+func TestCheckSMTPForMXOK_LastStatusCode(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@example.test")
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer())
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.test."}, "example.test", "someone")
+	assert.NoError(t, err)
+	assert.Equal(t, 250, smtp.LastStatusCode)
+	assert.Equal(t, "OK", smtp.LastResponse)
+
+	smtp, err = verifier.CheckSMTPForMX([]string{"mx.example.test."}, "example.test", "nobody")
+	assert.Error(t, err)
+	assert.Equal(t, 550, smtp.LastStatusCode)
+	assert.Equal(t, "5.1.1 user unknown", smtp.LastResponse)
+}
+
+func TestCheckSMTPForMXOK_LastStatusCodeCatchAll(t *testing.T) {
+	srv := newMockSMTPServer(t)
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer())
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.test."}, "example.test", "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.CatchAll)
+	assert.Equal(t, 250, smtp.LastStatusCode)
+}
+
+func TestCheckSMTPForMXOK_Transcript(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "RCPT" && arg == "TO:<someone@example.com>" {
+			return "550-5.1.1 The email account does not exist\r\n550 5.1.1 Try again"
+		}
+		return ""
+	}
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer()).EnableSMTPTranscript()
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.Error(t, err)
+	for i := range smtp.Transcript {
+		assert.Positive(t, smtp.Transcript[i].Duration)
+		smtp.Transcript[i].Duration = 0
+	}
+	assert.Equal(t, []SMTPStep{
+		{Code: 220, Message: "mock.local ESMTP ready"},
+		{Command: "EHLO localhost", Code: 250, Message: "mock.local"},
+		{Command: "MAIL FROM:<user@example.org>", Code: 250, Message: "OK"},
+		{Command: "RCPT TO:<someone@example.com>", Code: 550, Message: "5.1.1 The email account does not exist\n5.1.1 Try again"},
+	}, smtp.Transcript)
+}
+
+func TestCheckSMTPForMXOK_TranscriptStartTLS(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.tlsConfig = mockTLSConfig()
+
+	verifier := NewVerifier().EnableSMTPCheck().
+		EnableCustomDialer(srv.dialer()).
+		EnableSMTPTLS().
+		SMTPTLSConfig(&tls.Config{InsecureSkipVerify: true}). // #nosec
+		EnableSMTPTranscript()
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.TLS)
+
+	var commands []string
+	for _, step := range smtp.Transcript {
+		commands = append(commands, step.Command)
+	}
+	assert.Equal(t, []string{"", "EHLO localhost", "STARTTLS", "MAIL FROM:<user@example.org>", "RCPT TO:<someone@example.com>"}, commands)
+	assert.Equal(t, 220, smtp.Transcript[2].Code)
+	assert.Equal(t, 250, smtp.Transcript[4].Code)
+}
+
+func TestCheckSMTPForMXOK_TranscriptDisabled(t *testing.T) {
+	srv := newMockSMTPServer(t)
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer())
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.NoError(t, err)
+	assert.Nil(t, smtp.Transcript)
+}
+
+func TestCheckSMTPForMXOK_Tarpitted(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "MAIL" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return ""
+	}
+
+	verifier := NewVerifier().EnableSMTPCheck().EnableCustomDialer(srv.dialer()).EnableSMTPTranscript()
+	smtp, err := verifier.CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.NoError(t, err)
+	assert.False(t, smtp.Tarpitted)
+	assert.GreaterOrEqual(t, smtp.SlowestReply, 100*time.Millisecond)
+	assert.GreaterOrEqual(t, smtp.Transcript[2].Duration, 100*time.Millisecond)
+	assert.Less(t, smtp.Transcript[1].Duration, 100*time.Millisecond)
+
+	smtp, err = verifier.TarpitThreshold(50*time.Millisecond).CheckSMTPForMX([]string{"mx.example.com."}, "example.com", "someone")
+	assert.NoError(t, err)
+	assert.True(t, smtp.Tarpitted)
+	assert.True(t, smtp.Deliverable)
+}
@@ -0,0 +1,53 @@
+package emailverifier
+
+import "strings"
+
+// SMTPVRFY is the reply of the server to the VRFY command, or to the EXPN command when VRFY was refused,
+// see EnableVRFY
+type SMTPVRFY struct {
+	Command   string `json:"command"`   // "VRFY" or "EXPN"
+	Code      int    `json:"code"`      // the reply code, e.g. 250, 252 or 502, zero when no reply was received
+	Message   string `json:"message"`   // the reply text, e.g. the mailbox or the members of the list
+	Confirmed bool   `json:"confirmed"` // whether the server confirmed the address, so RCPT TO was not needed
+}
+
+// EnableVRFY asks the server to confirm the address by the VRFY command, and by the EXPN command
+// for the mailing lists when VRFY is refused, before the RCPT TO command. The address confirmed (250 or 251)
+// is deliverable without RCPT TO, any other reply, e.g. 252 or 502 of the servers which disable the commands,
+// falls back to RCPT TO. The reply is reported in SMTP.VRFY. Few servers still answer these commands.
+func (v *Verifier) EnableVRFY() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.vrfyEnabled = true
+	return v
+}
+
+// DisableVRFY disables the VRFY and EXPN commands (default)
+func (v *Verifier) DisableVRFY() *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.vrfyEnabled = false
+	return v
+}
+
+// vrfy asks the MX host to confirm the address by VRFY, or by EXPN when VRFY is refused,
+// and reports whether it has been confirmed
+func (v *Verifier) vrfy(client *smtpClient, host, email string, ret *SMTP) bool {
+	for _, command := range []string{"VRFY", "EXPN"} {
+		code, msg, err := client.cmd(command+" %s", email)
+		v.debug("smtp "+strings.ToLower(command), "host", host, "status", code, "response", msg, "error", err)
+		// 252 accepts the address without confirming it
+		confirmed := err == nil && (code == 250 || code == 251)
+		ret.VRFY = &SMTPVRFY{Command: command, Code: code, Message: msg, Confirmed: confirmed}
+		if confirmed {
+			return true
+		}
+		if code == 0 {
+			// The connection failed, RCPT TO reports it
+			return false
+		}
+	}
+	return false
+}
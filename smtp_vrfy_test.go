@@ -0,0 +1,69 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSMTPOK_VRFYConfirmed(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	accept := rejectRandomRCPT()
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "VRFY" && arg == "john@example.test" {
+			return "250 2.1.5 John Doe <john@example.test>"
+		}
+		return accept(cmd, arg)
+	}
+
+	smtp, err := srv.verifier("example.test").EnableVRFY().CheckSMTP("example.test", "john")
+	assert.NoError(t, err)
+	assert.True(t, smtp.Deliverable)
+	assert.Equal(t, &SMTPVRFY{Command: "VRFY", Code: 250, Message: "2.1.5 John Doe <john@example.test>", Confirmed: true}, smtp.VRFY)
+
+	// The catch-all probe only, the confirmed address is not probed by RCPT TO
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 1)
+	assert.Empty(t, filterCommands(srv.received(), "EXPN"))
+}
+
+func TestCheckSMTPOK_EXPNConfirmed(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		switch cmd {
+		case "VRFY":
+			return "252 2.5.2 Cannot VRFY user"
+		case "EXPN":
+			return "250-John Doe <john@example.test>\r\n250 Jane Doe <jane@example.test>"
+		}
+		return ""
+	}
+
+	smtp, err := srv.verifier("example.test").DisableCatchAllCheck().EnableVRFY().CheckSMTP("example.test", "team")
+	assert.NoError(t, err)
+	assert.True(t, smtp.Deliverable)
+	assert.Equal(t, "EXPN", smtp.VRFY.Command)
+	assert.Equal(t, "John Doe <john@example.test>\nJane Doe <jane@example.test>", smtp.VRFY.Message)
+	assert.Equal(t, []string{"VRFY team@example.test"}, filterCommands(srv.received(), "VRFY"))
+	assert.Empty(t, filterCommands(srv.received(), "RCPT"))
+}
+
+func TestCheckSMTPOK_VRFYRefusedFallsBackToRCPT(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT()
+
+	// The mock server does not implement VRFY and EXPN (502)
+	smtp, err := srv.verifier("example.test").DisableCatchAllCheck().EnableVRFY().CheckSMTP("example.test", "john")
+	assert.Error(t, err)
+	assert.False(t, smtp.Deliverable)
+	assert.Equal(t, &SMTPVRFY{Command: "EXPN", Code: 502, Message: "Command not implemented"}, smtp.VRFY)
+	assert.Equal(t, 550, smtp.LastStatusCode)
+}
+
+func TestCheckSMTPOK_VRFYDisabled(t *testing.T) {
+	srv := newMockSMTPServer(t)
+
+	smtp, err := srv.verifier("example.test").DisableCatchAllCheck().CheckSMTP("example.test", "john")
+	assert.NoError(t, err)
+	assert.Nil(t, smtp.VRFY)
+	assert.Empty(t, filterCommands(srv.received(), "VRFY"))
+}
@@ -0,0 +1,66 @@
+package emailverifier
+
+import (
+	"strings"
+	"sync"
+)
+
+// stringSet is a goroutine-safe set of lowercased strings
+type stringSet struct {
+	mu    sync.RWMutex
+	items map[string]struct{}
+}
+
+// newStringSet creates a new set holding the items
+func newStringSet(items []string) *stringSet {
+	s := &stringSet{items: map[string]struct{}{}}
+	s.add(items)
+	return s
+}
+
+// add adds the items to the set, empty items are ignored
+func (s *stringSet) add(items []string) {
+	s.mu.Lock()
+	for _, item := range items {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" {
+			s.items[item] = struct{}{}
+		}
+	}
+	s.mu.Unlock()
+}
+
+// has checks if the set contains the item
+func (s *stringSet) has(item string) bool {
+	s.mu.RLock()
+	_, ok := s.items[strings.ToLower(item)]
+	s.mu.RUnlock()
+	return ok
+}
+
+// hasHost checks if the set contains the host, or any of its parent domains
+func (s *stringSet) hasHost(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	for host != "" {
+		if s.has(host) {
+			return true
+		}
+		i := strings.Index(host, ".")
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	return false
+}
+
+// snapshot returns a copy of the items
+func (s *stringSet) snapshot() map[string]bool {
+	s.mu.RLock()
+	ret := make(map[string]bool, len(s.items))
+	for item := range s.items {
+		ret[item] = true
+	}
+	s.mu.RUnlock()
+	return ret
+}
@@ -0,0 +1,20 @@
+package emailverifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringSet(t *testing.T) {
+	s := newStringSet([]string{"Foo", " bar ", ""})
+
+	assert.True(t, s.has("foo"))
+	assert.True(t, s.has("FOO"))
+	assert.True(t, s.has("bar"))
+	assert.False(t, s.has(""))
+	assert.False(t, s.has("baz"))
+
+	s.add([]string{"baz"})
+	assert.True(t, s.has("baz"))
+}
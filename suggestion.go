@@ -1,19 +1,42 @@
 package emailverifier
 
 import (
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/hbollon/go-edlib"
+	"golang.org/x/net/publicsuffix"
 )
 
-// SuggestDomain checks if domain has a typo and suggests a similar correct domain from metadata,
-// returns a suggestion
+// SuggestDomain checks if domain has a typo or is a homoglyph of a popular domain
+// and suggests a similar correct domain from metadata, returns a suggestion
 func (v *Verifier) SuggestDomain(domain string) string {
+	return v.snapshot().suggestDomain(domain)
+}
+
+// suggestDomain suggests a similar correct domain, see SuggestDomain
+func (v *Verifier) suggestDomain(domain string) string {
 	if domain == "" {
 		return ""
 	}
 
+	// Homoglyphs of popular domains are suggested regardless of their edit distance
+	if lookalike := v.LookalikeDomain(domain); lookalike != "" {
+		return lookalike
+	}
+
 	domain = strings.ToLower(domain)
+	if fixed := v.suggestTLD(domain); fixed != "" {
+		_, tld := splitDomain(domain)
+		corrected := strings.TrimSuffix(domain, tld) + fixed
+		// The second level domain may be misspelled too
+		if suggestion := v.suggestDomain(corrected); suggestion != "" {
+			return suggestion
+		}
+		return corrected
+	}
+
 	sld, tld := splitDomain(domain)
 	// If the domain is a valid second level domain and top level domain, do not suggest anything
 	if sld != "" && tld != "" {
@@ -23,7 +46,11 @@ func (v *Verifier) SuggestDomain(domain string) string {
 
 	}
 
-	closestDomain := findClosestDomain(domain, freeDomains, domainThreshold)
+	// The providers of the locale win over the global ones, unless the domain is a known one
+	closestDomain := findClosestDomain(domain, v.domainSuggestThreshold, v.localeDomains)
+	if closestDomain == "" || freeDomains[domain] || v.suggestionDomains.has(domain) {
+		closestDomain = findClosestDomain(domain, v.domainSuggestThreshold, freeDomains, v.suggestionDomains.snapshot())
+	}
 	if closestDomain != "" {
 		if closestDomain == domain {
 			// The domain exactly matches one of the suggestion domains, no suggestion provided.
@@ -36,8 +63,8 @@ func (v *Verifier) SuggestDomain(domain string) string {
 	var localTypo bool
 	closestDomain = domain
 
-	closestSecondLevelDomain := findClosestDomain(sld, suggestionSecondLevelDomains, secondLevelThreshold)
-	closestTopLevelDomain := findClosestDomain(tld, suggestionTopLevelDomains, topLevelThreshold)
+	closestSecondLevelDomain := findClosestDomain(sld, secondLevelThreshold, suggestionSecondLevelDomains)
+	closestTopLevelDomain := findClosestDomain(tld, topLevelThreshold, suggestionTopLevelDomains)
 
 	if closestSecondLevelDomain != "" && closestSecondLevelDomain != sld {
 		localTypo = true
@@ -56,24 +83,139 @@ func (v *Verifier) SuggestDomain(domain string) string {
 	return ""
 }
 
-// findClosestDomain finds the string most similar to the domain via Levenshtein algorithms.
-func findClosestDomain(domain string, domains map[string]bool, threshold float32) string {
+// SuggestUsername suggests the candidate closest to the misspelled username, e.g. "john.doe" for "jonh.doe",
+// when given the known usernames at the domain, e.g. from a directory. It returns an empty string when the
+// username is one of the candidates or none of them is close enough. The match is case-insensitive,
+// a transposition of two adjacent characters counts as a single edit.
+func (v *Verifier) SuggestUsername(username string, candidates []string) string {
+	username = strings.ToLower(username)
+	if username == "" {
+		return ""
+	}
+
 	var maxDist = float32(-1)
-	var closestDomain string
+	var closest string
+	for _, c := range candidates {
+		if strings.ToLower(c) == username {
+			return ""
+		}
+		if dist, _ := edlib.StringsSimilarity(username, strings.ToLower(c), edlib.OSADamerauLevenshtein); dist > maxDist {
+			maxDist, closest = dist, c
+		}
+	}
+	if maxDist >= usernameThreshold {
+		return closest
+	}
+	return ""
+}
 
-	if domain == "" || len(domains) == 0 {
-		return closestDomain
+// SuggestTLD checks if the top level domain of the domain is misspelled, e.g. .con instead of .com,
+// and returns the corrected top level domain, or an empty string otherwise
+func (v *Verifier) SuggestTLD(domain string) string {
+	return v.snapshot().suggestTLD(strings.ToLower(domain))
+}
+
+// suggestTLD corrects the common typos of the top level domain and the ones which are not
+// valid top level domains but close to a popular one
+func (v *Verifier) suggestTLD(domain string) string {
+	sld, tld := splitDomain(domain)
+	if sld == "" {
+		return ""
 	}
 
-	for d := range domains {
-		if domain == d {
-			return domain
+	if fixed, ok := suggestionTLDTypos[tld]; ok {
+		corrected := strings.TrimSuffix(domain, tld) + fixed
+		if !isKnownTLD(tld) || freeDomains[corrected] || v.suggestionDomains.has(corrected) || v.localeDomains[corrected] {
+			return fixed
 		}
+		return ""
+	}
+	if isKnownTLD(tld) {
+		return ""
+	}
+
+	var maxDist = float32(-1)
+	var closest string
+	for _, t := range popularTLDs() {
+		if dist, _ := edlib.StringsSimilarity(tld, t, edlib.Levenshtein); dist > maxDist {
+			maxDist, closest = dist, t
+		}
+	}
+	if maxDist >= topLevelThreshold {
+		return closest
+	}
+	return ""
+}
+
+// isKnownTLD checks if the top level domain is in the ICANN section of the public suffix list,
+// i.e. it is delegated by IANA
+func isKnownTLD(tld string) bool {
+	_, icann := publicsuffix.PublicSuffix("domain." + tld)
+	return icann
+}
+
+var (
+	popularTLDsOnce sync.Once
+	popularTLDList  []string
+)
+
+// popularTLDs returns the single-label top level domains of the suggestions, sorted to break the ties
+// of the corrections deterministically
+func popularTLDs() []string {
+	popularTLDsOnce.Do(func() {
+		for t := range suggestionTopLevelDomains {
+			if !strings.Contains(t, ".") {
+				popularTLDList = append(popularTLDList, t)
+			}
+		}
+		sort.Strings(popularTLDList)
+	})
+	return popularTLDList
+}
+
+// localeSuggestionDomains returns the domains preferred for the locale, e.g. "de", "de-AT" or "pt_BR",
+// or nil for an unknown locale
+func localeSuggestionDomains(locale string) map[string]bool {
+	parts := strings.FieldsFunc(strings.ToLower(locale), func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return nil
+	}
+
+	domains := append([]string(nil), suggestionLanguageDomains[parts[0]]...)
+	if len(parts) > 1 {
+		domains = append(domains, suggestionRegionDomains[parts[len(parts)-1]]...)
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+
+	ret := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		ret[d] = true
+	}
+	return ret
+}
+
+// findClosestDomain finds the string most similar to the domain among the domain sets via Levenshtein algorithms.
+func findClosestDomain(domain string, threshold float32, domainSets ...map[string]bool) string {
+	var maxDist = float32(-1)
+	var closestDomain string
+
+	if domain == "" {
+		return closestDomain
+	}
 
-		dist, _ := edlib.StringsSimilarity(domain, d, edlib.Levenshtein)
-		if dist > maxDist {
-			maxDist = dist
-			closestDomain = d
+	for _, domains := range domainSets {
+		for d := range domains {
+			if domain == d {
+				return domain
+			}
+
+			dist, _ := edlib.StringsSimilarity(domain, d, edlib.Levenshtein)
+			if dist > maxDist {
+				maxDist = dist
+				closestDomain = d
+			}
 		}
 	}
 
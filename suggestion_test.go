@@ -47,3 +47,97 @@ func TestSuggestDomainOK_SLD(t *testing.T) {
 	ret := verifier.SuggestDomain(domain)
 	assert.Equal(t, "hotmail.aftership", ret)
 }
+
+func TestSuggestDomainOK_CustomDomain(t *testing.T) {
+	v := NewVerifier().AddSuggestionDomains([]string{"mail.acme-corp.com"})
+
+	assert.Equal(t, "mail.acme-corp.com", v.SuggestDomain("mail.acme-crop.com"))
+	assert.Equal(t, "", v.SuggestDomain("mail.acme-corp.com"))
+	assert.Equal(t, "", NewVerifier().SuggestDomain("mail.acme-crop.com"))
+}
+
+func TestSuggestDomainOK_CustomDomainLookalike(t *testing.T) {
+	v := NewVerifier().AddSuggestionDomains([]string{"acme-corp.com"})
+
+	assert.Equal(t, "acme-corp.com", v.LookalikeDomain("аcme-corp.com"))
+	assert.Equal(t, "", v.LookalikeDomain("acme-corp.com"))
+}
+
+func TestSuggestDomainOK_Threshold(t *testing.T) {
+	domain := "gmaiiil.com"
+
+	assert.Equal(t, "", NewVerifier().SuggestDomain(domain))
+	assert.Equal(t, "gmail.com", NewVerifier().DomainSuggestThreshold(0.7).SuggestDomain(domain))
+}
+
+func TestSuggestDomainOK_Locale(t *testing.T) {
+	assert.Equal(t, "web.de", NewVerifier().SuggestionLocale("de").SuggestDomain("wef.de"))
+	assert.Equal(t, "yandex.ru", NewVerifier().SuggestionLocale("ru-RU").SuggestDomain("yandex.pu"))
+	assert.Equal(t, "mail.ru", NewVerifier().SuggestionLocale("ru").SuggestDomain("gail.ru"))
+	assert.Equal(t, "gmx.at", NewVerifier().SuggestionLocale("de_AT").SuggestDomain("gmx.aq"))
+	// The known domains are not replaced by the ones of the locale
+	assert.Equal(t, "", NewVerifier().SuggestionLocale("ru").SuggestDomain("gmail.ru"))
+}
+
+func TestLocaleSuggestionDomains(t *testing.T) {
+	assert.Nil(t, localeSuggestionDomains(""))
+	assert.Nil(t, localeSuggestionDomains("xx-YY"))
+	assert.True(t, localeSuggestionDomains("DE")["gmx.de"])
+	assert.True(t, localeSuggestionDomains("pt-BR")["sapo.pt"])
+	assert.True(t, localeSuggestionDomains("pt-BR")["ig.com.br"])
+	assert.True(t, localeSuggestionDomains("en-GB")["btinternet.com"])
+}
+
+func TestSuggestUsername(t *testing.T) {
+	candidates := []string{"john.doe", "jane.doe", "Support"}
+	cases := map[string]string{
+		"jonh.doe": "john.doe",
+		"jane.do":  "jane.doe",
+		"suport":   "Support",
+		"john.doe": "",
+		"SUPPORT":  "",
+		"someone":  "",
+		"":         "",
+	}
+	for username, suggestion := range cases {
+		assert.Equal(t, suggestion, verifier.SuggestUsername(username, candidates), username)
+	}
+	assert.Empty(t, verifier.SuggestUsername("jonh.doe", nil))
+}
+
+func TestSuggestTLD(t *testing.T) {
+	cases := map[string]string{
+		"gmail.con":   "com",
+		"acme.cpm":    "com",
+		"acme.comm":   "com",
+		"acme.nte":    "net",
+		"acme.orgg":   "org",
+		"gmail.edd":   "edu",
+		"hotmail.cm":  "com",
+		"acme.cm":     "", // a valid top level domain corrected only for the known domains
+		"acme.xyz":    "",
+		"acme.com":    "",
+		"com":         "",
+		"GMAIL.CON":   "com",
+		"acme.qwerty": "",
+	}
+	for domain, tld := range cases {
+		assert.Equal(t, tld, verifier.SuggestTLD(domain), domain)
+	}
+}
+
+func TestSuggestDomainOK_TLDTypo(t *testing.T) {
+	assert.Equal(t, "gmail.com", verifier.SuggestDomain("gmail.con"))
+	assert.Equal(t, "acme.com", verifier.SuggestDomain("acme.con"))
+	assert.Equal(t, "gmail.com", verifier.SuggestDomain("gmai.con"))
+	assert.Equal(t, "gmail.com", verifier.SuggestDomain("gmail.co"))
+}
+
+func TestCheckEmail_SuggestedTLD(t *testing.T) {
+	v := NewVerifier().EnableDomainSuggest().EnableMXResolver(&mockResolver{})
+
+	ret, err := v.Verify("someone@acme.con")
+	assert.Error(t, err)
+	assert.Equal(t, "acme.com", ret.Suggestion)
+	assert.Equal(t, "com", ret.SuggestedTLD)
+}
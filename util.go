@@ -21,8 +21,9 @@ func splitDomain(domain string) (string, string) {
 
 // DomainToASCII converts any internationalized domain names to ASCII
 // reference: https://en.wikipedia.org/wiki/Punycode
+// It is lossy: a domain that cannot be converted is returned unchanged, use DomainToASCIIE to get the error.
 func DomainToASCII(domain string) string {
-	asciiDomain, err := idna.ToASCII(domain)
+	asciiDomain, err := DomainToASCIIE(domain)
 	if err != nil {
 		return domain
 	}
@@ -30,6 +31,12 @@ func DomainToASCII(domain string) string {
 
 }
 
+// DomainToASCIIE converts any internationalized domain names to ASCII like DomainToASCII,
+// but returns the error of the conversion, e.g. for invalid punycode
+func DomainToASCIIE(domain string) (string, error) {
+	return idna.ToASCII(domain)
+}
+
 // callJobFuncWithParams convert jobFunc and prams to a specific function and call it
 func callJobFuncWithParams(jobFunc interface{}, params []interface{}) []reflect.Value {
 	typ := reflect.TypeOf(jobFunc)
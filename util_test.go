@@ -21,6 +21,16 @@ func TestDomainToASCII_NormalDomain(t *testing.T) {
 	assert.Equal(t, expected, ret)
 }
 
+func TestDomainToASCIIE(t *testing.T) {
+	ret, err := DomainToASCIIE("münchen.de")
+	assert.NoError(t, err)
+	assert.Equal(t, "xn--mnchen-3ya.de", ret)
+
+	_, err = DomainToASCIIE("xn--zz.com")
+	assert.Error(t, err)
+	assert.Equal(t, "xn--zz.com", DomainToASCII("xn--zz.com"))
+}
+
 func TestCallJobFuncWithParams_NoOutput(t *testing.T) {
 	f := func(a string) { fmt.Println(a) }
 	ret := callJobFuncWithParams(f, []interface{}{"testing"})
@@ -0,0 +1,54 @@
+package emailverifier
+
+import (
+	"errors"
+	"net"
+)
+
+// ValidationIssue is a problem with an email address found by ValidateOffline
+type ValidationIssue struct {
+	Code    string `json:"code"`    // one of ReasonInvalidSyntax, ReasonDisposable, ReasonRoleAccount, ReasonNoMXRecords, ReasonDomainNotFound and ReasonDNSError
+	Message string `json:"message"` // human-readable description of the problem
+}
+
+// ValidateOffline validates the address without contacting the mail server, e.g. for the feedback of a form,
+// and returns all the problems found: invalid syntax, disposable domain, role account and missing MX records.
+// The address is valid when no issue is returned. The further checks are skipped for an invalid syntax.
+func (v *Verifier) ValidateOffline(email string) []ValidationIssue {
+	v = v.snapshot()
+
+	syntax := v.parseAddress(email)
+	if !syntax.Valid {
+		message := "The email address is not valid"
+		switch {
+		case syntax.Reason != "":
+			message += ": " + syntax.Reason
+		case syntax.LocalPartTooLong:
+			message += ": " + SyntaxErrLocalPartTooLong
+		case syntax.DomainTooLong:
+			message += ": " + SyntaxErrDomainTooLong
+		}
+		return []ValidationIssue{{Code: ReasonInvalidSyntax, Message: message}}
+	}
+
+	var issues []ValidationIssue
+	if disposable, _ := v.checkDisposable(syntax.Domain); disposable {
+		issues = append(issues, ValidationIssue{Code: ReasonDisposable, Message: "The domain provides disposable email addresses"})
+	}
+	if v.isRoleAccount(syntax.Username) {
+		issues = append(issues, ValidationIssue{Code: ReasonRoleAccount, Message: "The address belongs to a role rather than a person"})
+	}
+
+	mx, err := v.checkMX(syntax.Domain)
+	var dnsErr *net.DNSError
+	switch {
+	case err != nil && errors.As(err, &dnsErr) && !dnsErr.IsNotFound:
+		issues = append(issues, ValidationIssue{Code: ReasonDNSError, Message: "The MX records of the domain could not be resolved"})
+	case err != nil && isNotFoundDNSError(err) && v.domainNotFound(syntax.Domain):
+		issues = append(issues, ValidationIssue{Code: ReasonDomainNotFound, Message: "The domain does not exist"})
+	case err != nil || !mx.HasMXRecord:
+		issues = append(issues, ValidationIssue{Code: ReasonNoMXRecords, Message: "The domain does not accept email"})
+	}
+
+	return issues
+}
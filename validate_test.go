@@ -0,0 +1,59 @@
+package emailverifier
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOffline(t *testing.T) {
+	dr := newDisposableRepo()
+	dr.AddDisposableDomains([]string{"disposable.test"})
+	v := NewVerifier().
+		EnableDisposableCheck(dr).
+		EnableMXResolver(&mockResolver{
+			mx:    map[string][]*net.MX{"example.test": {{Host: "mx.example.test.", Pref: 10}}},
+			hosts: map[string][]string{"nomx.test": {"192.0.2.1"}, "disposable.test": {"192.0.2.2"}},
+		})
+
+	cases := map[string][]string{
+		"someone@example.test":   nil,
+		"admin@example.test":     {ReasonRoleAccount},
+		"admin@disposable.test":  {ReasonDisposable, ReasonRoleAccount, ReasonNoMXRecords},
+		"someone@nomx.test":      {ReasonNoMXRecords},
+		"someone@nx.test":        {ReasonDomainNotFound},
+		"invalid":                {ReasonInvalidSyntax},
+		"admin@@disposable.test": {ReasonInvalidSyntax},
+	}
+	for email, codes := range cases {
+		var got []string
+		for _, issue := range v.ValidateOffline(email) {
+			assert.NotEmpty(t, issue.Message, email)
+			got = append(got, issue.Code)
+		}
+		assert.Equal(t, codes, got, email)
+	}
+}
+
+func TestValidateOffline_SyntaxMessage(t *testing.T) {
+	issues := NewVerifier().ValidateOffline(strings.Repeat("a", 65) + "@example.test")
+	assert.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Message, SyntaxErrLocalPartTooLong)
+
+	issues = NewVerifier().EnableStrictSyntax().ValidateOffline("john..doe@example.test")
+	assert.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Message, SyntaxErrConsecutiveDots)
+}
+
+func TestValidateOffline_DNSError(t *testing.T) {
+	resolver := &flakyResolver{
+		mockResolver: &mockResolver{},
+		failures:     1,
+		err:          &net.DNSError{Err: "server misbehaving", Name: "example.test", IsTemporary: true},
+	}
+
+	issues := NewVerifier().EnableMXResolver(resolver).ValidateOffline("someone@example.test")
+	assert.Equal(t, []ValidationIssue{{Code: ReasonDNSError, Message: "The MX records of the domain could not be resolved"}}, issues)
+}
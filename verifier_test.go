@@ -1,7 +1,13 @@
 package emailverifier
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,14 +25,17 @@ func TestCheckEmailOK_SMTPHostNotExists(t *testing.T) {
 	expected := Result{
 		Email: email,
 		Syntax: Syntax{
-			Username: username,
-			Domain:   domain,
-			Valid:    true,
+			Username:     username,
+			Domain:       domain,
+			Valid:        true,
+			BaseUsername: username,
 		},
 		HasMxRecords: false,
 		Disposable:   false,
 		RoleAccount:  false,
-		Reachable:    reachableUnknown,
+		Reachable:    reachableNo,
+		Score:        20,
+		Reason:       ReasonDomainNotFound,
 		Free:         false,
 		SMTP:         nil,
 	}
@@ -43,28 +52,36 @@ func TestCheckEmailOK_SMTPHostExists_NotCatchAll(t *testing.T) {
 		email    = address
 	)
 
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier(domain).EnableDisposableCheck(newDisposableRepo())
+
 	ret, err := verifier.Verify(email)
 	expected := Result{
 		Email: email,
 		Syntax: Syntax{
-			Username: username,
-			Domain:   domain,
-			Valid:    true,
+			Username:     username,
+			Domain:       domain,
+			Valid:        true,
+			BaseUsername: username,
 		},
 		HasMxRecords: true,
 		Reachable:    reachableUnknown,
+		Score:        65,
+		Reason:       ReasonCatchAll,
 		Disposable:   false,
 		RoleAccount:  false,
 		Free:         false,
 		SMTP: &SMTP{
-			HostExists:  true,
-			FullInbox:   false,
-			CatchAll:    true,
-			Deliverable: false,
-			Disabled:    false,
+			HostExists:     true,
+			FullInbox:      false,
+			CatchAll:       true,
+			CatchAllStatus: CatchAllYes,
+			Deliverable:    false,
+			Disabled:       false,
 		},
 	}
 	assert.Nil(t, err)
+	ret.SMTP = withoutServerDetails(ret.SMTP)
 	assert.Equal(t, &expected, ret)
 }
 
@@ -77,16 +94,21 @@ func TestCheckEmailOK_SMTPHostExists_FreeDomain(t *testing.T) {
 		email    = address
 	)
 
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier(domain).EnableDisposableCheck(newDisposableRepo())
+
 	ret, err := verifier.Verify(email)
 	expected := Result{
 		Email: email,
 		Syntax: Syntax{
-			Username: username,
-			Domain:   domain,
-			Valid:    true,
+			Username:     username,
+			Domain:       domain,
+			Valid:        true,
+			BaseUsername: username,
 		},
 		HasMxRecords: true,
-		Reachable:    reachableNo,
+		Reachable:    reachableYes,
+		Score:        95,
 		Disposable:   false,
 		RoleAccount:  false,
 		Free:         true,
@@ -94,12 +116,14 @@ func TestCheckEmailOK_SMTPHostExists_FreeDomain(t *testing.T) {
 			HostExists:  true,
 			FullInbox:   false,
 			CatchAll:    false,
-			Deliverable: false,
+			Deliverable: true,
 			Disabled:    false,
 		},
 	}
 	assert.Nil(t, err)
+	ret.SMTP = withoutServerDetails(ret.SMTP)
 	assert.Equal(t, &expected, ret)
+	assert.Len(t, filterCommands(srv.received(), "RCPT"), 1)
 }
 
 func TestCheckEmail_ErrorSyntax(t *testing.T) {
@@ -121,6 +145,8 @@ func TestCheckEmail_ErrorSyntax(t *testing.T) {
 		},
 		HasMxRecords: false,
 		Reachable:    reachableUnknown,
+		Score:        0,
+		Reason:       ReasonInvalidSyntax,
 		Disposable:   false,
 		RoleAccount:  false,
 		Free:         false,
@@ -143,12 +169,15 @@ func TestCheckEmail_Disposable(t *testing.T) {
 	expected := Result{
 		Email: email,
 		Syntax: Syntax{
-			Username: username,
-			Domain:   domain,
-			Valid:    true,
+			Username:     username,
+			Domain:       domain,
+			Valid:        true,
+			BaseUsername: username,
 		},
 		HasMxRecords: false,
 		Reachable:    reachableUnknown,
+		Score:        0,
+		Reason:       ReasonDisposable,
 		Disposable:   true,
 		RoleAccount:  false,
 		Free:         false,
@@ -174,12 +203,15 @@ func TestCheckEmail_Disposable_override(t *testing.T) {
 	expected := Result{
 		Email: email,
 		Syntax: Syntax{
-			Username: username,
-			Domain:   domain,
-			Valid:    true,
+			Username:     username,
+			Domain:       domain,
+			Valid:        true,
+			BaseUsername: username,
 		},
 		HasMxRecords: false,
 		Reachable:    reachableUnknown,
+		Score:        0,
+		Reason:       ReasonDisposable,
 		Disposable:   true,
 		RoleAccount:  false,
 		Free:         false,
@@ -202,24 +234,29 @@ func TestCheckEmail_RoleAccount(t *testing.T) {
 	expected := Result{
 		Email: email,
 		Syntax: Syntax{
-			Username: username,
-			Domain:   domain,
-			Valid:    true,
+			Username:     username,
+			Domain:       domain,
+			Valid:        true,
+			BaseUsername: username,
 		},
 		HasMxRecords: true,
 		Reachable:    reachableUnknown,
+		Score:        55,
+		Reason:       ReasonCatchAll,
 		Disposable:   false,
 		RoleAccount:  true,
 		Free:         false,
 		SMTP: &SMTP{
-			HostExists:  true,
-			FullInbox:   false,
-			CatchAll:    true,
-			Deliverable: false,
-			Disabled:    false,
+			HostExists:     true,
+			FullInbox:      false,
+			CatchAll:       true,
+			CatchAllStatus: CatchAllYes,
+			Deliverable:    false,
+			Disabled:       false,
 		},
 	}
 	assert.Nil(t, err)
+	ret.SMTP = withoutServerDetails(ret.SMTP)
 	assert.Equal(t, &expected, ret)
 }
 
@@ -237,14 +274,17 @@ func TestCheckEmail_DisabledSMTPCheck(t *testing.T) {
 	expected := Result{
 		Email: email,
 		Syntax: Syntax{
-			Username: username,
-			Domain:   domain,
-			Valid:    true,
+			Username:     username,
+			Domain:       domain,
+			Valid:        true,
+			BaseUsername: username,
 		},
 		HasMxRecords: true,
 		Disposable:   false,
 		RoleAccount:  false,
 		Reachable:    reachableUnknown,
+		Score:        50,
+		Reason:       ReasonSMTPCheckDisabled,
 		Free:         false,
 		SMTP:         nil,
 	}
@@ -253,6 +293,65 @@ func TestCheckEmail_DisabledSMTPCheck(t *testing.T) {
 	assert.Equal(t, &expected, ret)
 }
 
+func TestCheckEmail_MXError(t *testing.T) {
+	resolver := &flakyResolver{
+		mockResolver: &mockResolver{},
+		failures:     1,
+		err:          &net.DNSError{Err: "server misbehaving", Name: "example.test", IsTemporary: true},
+	}
+	verifier := NewVerifier().EnableSMTPCheck().EnableMXResolver(resolver)
+
+	ret, err := verifier.Verify("someone@example.test")
+	var mxErr *MXError
+	assert.True(t, errors.As(err, &mxErr))
+	var dnsErr *net.DNSError
+	assert.True(t, errors.As(err, &dnsErr))
+	assert.Equal(t, dnsErr.Error(), err.Error())
+	assert.True(t, ret.Syntax.Valid)
+	assert.Equal(t, ReasonDNSError, ret.Reason)
+}
+
+func TestCheckEmail_SMTPError(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "RCPT" {
+			return "421 4.7.0 try again later"
+		}
+		return ""
+	}
+	verifier := srv.verifier("example.test").DisableCatchAllCheck()
+
+	ret, err := verifier.Verify("someone@example.test")
+	var smtpErr *SMTPError
+	assert.True(t, errors.As(err, &smtpErr))
+	var mxErr *MXError
+	assert.False(t, errors.As(err, &mxErr))
+	var le *LookupError
+	assert.True(t, errors.As(err, &le))
+	assert.Equal(t, ErrTryAgainLater, le.Message)
+	assert.True(t, ret.HasMxRecords)
+	assert.Equal(t, ReasonTryAgainLater, ret.Reason)
+}
+
+func TestVerifyFromEmailDomain(t *testing.T) {
+	resolver := &mockResolver{mx: map[string][]*net.MX{
+		"sender.test":  {{Host: "mx.sender.test.", Pref: 10}},
+		"nullmx.test":  {{Host: ".", Pref: 0}},
+		"example.test": {{Host: "mx.example.test.", Pref: 10}},
+	}}
+	v := NewVerifier().EnableMXResolver(resolver)
+
+	assert.False(t, v.FromEmail("user@nomx.test").VerifyFromEmailDomain())
+	assert.False(t, v.FromEmail("user@nullmx.test").VerifyFromEmailDomain())
+	assert.False(t, v.FromEmail("invalid").VerifyFromEmailDomain())
+	assert.True(t, v.FromEmail("user@sender.test").VerifyFromEmailDomain())
+
+	assert.NoError(t, v.SelectFromEmail([]string{"user@nomx.test", "user@nullmx.test", "user@example.test", "user@sender.test"}))
+	assert.Equal(t, "user@example.test", v.fromEmail)
+	assert.ErrorIs(t, v.SelectFromEmail([]string{"user@nomx.test"}), ErrUnroutableFromEmail)
+	assert.Equal(t, "user@example.test", v.fromEmail)
+}
+
 func TestNewVerifierOK_AutoUpdateDisposable(t *testing.T) {
 	verifier.EnableAutoUpdateDisposable()
 }
@@ -272,14 +371,88 @@ func TestNewVerifierOK_AutoUpdateDisposableDuplicate(t *testing.T) {
 	verifier.EnableAutoUpdateDisposable()
 }
 
-func TestStopCurrentSchedule_ScheduleIsNil(t *testing.T) {
+func TestDetachSchedule_ScheduleIsNil(t *testing.T) {
 	verifier.schedule = nil
-	verifier.stopCurrentSchedule()
+	verifier.detachSchedule()()
 }
 
-func TestStopCurrentScheduleOK(t *testing.T) {
+func TestDetachScheduleOK(t *testing.T) {
 	verifier.EnableAutoUpdateDisposable()
-	verifier.stopCurrentSchedule()
+	verifier.detachSchedule()()
+	assert.Nil(t, verifier.schedule)
+	assert.Nil(t, verifier.cancelUpdate)
+}
+
+func TestDisableAutoUpdateDisposable_CallbackCallsSetter(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody, Request: r}, nil
+	})}
+
+	updated := make(chan DisposableUpdate, 1)
+	v := NewVerifier().EnableDisposableCheck(newDisposableRepo()).DisposableHTTPClient(client).DisposableFetchRetry(3, time.Hour)
+	v.OnDisposableUpdate(func(u DisposableUpdate) {
+		// The setter must not block on the lock held by the stopping call
+		v.DisposableFetchTimeout(time.Second)
+		updated <- u
+	})
+	v.EnableAutoUpdateDisposableEvery(time.Hour)
+	<-requested
+
+	disabled := make(chan struct{})
+	go func() {
+		v.DisableAutoUpdateDisposable()
+		close(disabled)
+	}()
+	select {
+	case u := <-updated:
+		assert.Equal(t, 1, u.Attempts)
+		assert.Error(t, u.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the update was not cancelled")
+	}
+	select {
+	case <-disabled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("DisableAutoUpdateDisposable blocked")
+	}
+}
+
+func TestCloseOK(t *testing.T) {
+	v := NewVerifier().EnableDisposableCheck(newDisposableRepo()).EnableAutoUpdateDisposable().EnableMXCache(time.Minute)
+	v.mxCache.set("example.com", []*net.MX{{Host: "mx.example.com.", Pref: 10}}, 0)
+
+	assert.NoError(t, v.Close())
+	assert.Nil(t, v.schedule)
+	_, ok := v.mxCache.get("example.com")
+	assert.False(t, ok)
+
+	assert.NoError(t, v.Close())
+}
+
+func TestCloseOK_CancelsInitialUpdate(t *testing.T) {
+	requested, cancelled := make(chan struct{}), make(chan struct{})
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		close(requested)
+		<-r.Context().Done()
+		close(cancelled)
+		return nil, r.Context().Err()
+	})}
+
+	v := NewVerifier().EnableDisposableCheck(newDisposableRepo()).DisposableHTTPClient(client).DisposableFetchTimeout(time.Hour)
+	v.EnableAutoUpdateDisposableEvery(time.Hour)
+	<-requested
+
+	assert.NoError(t, v.Close())
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the initial update was not cancelled")
+	}
 }
 
 func TestCheckEmail_EnableDomainSuggest(t *testing.T) {
@@ -295,3 +468,121 @@ func TestCheckEmail_EnableDomainSuggest(t *testing.T) {
 
 	assert.Equal(t, ret.Suggestion, "")
 }
+
+func TestNewVerifierOK_EnableAutoUpdateDisposableEvery(t *testing.T) {
+	verifier := NewVerifier().EnableDisposableCheck(newDisposableRepo())
+	defer verifier.DisableAutoUpdateDisposable()
+
+	verifier.EnableAutoUpdateDisposableEvery(time.Hour)
+	assert.True(t, verifier.schedule.running)
+}
+
+// TestVerifier_ConcurrentUse toggles the options while verifying, run it with -race
+func TestVerifier_ConcurrentUse(t *testing.T) {
+	server := newMockSMTPServer(t)
+	resolver := &mockResolver{mx: map[string][]*net.MX{
+		"example.test": {{Host: "mx.example.test.", Pref: 10}},
+	}}
+	v := NewVerifier().
+		EnableSMTPCheck().
+		EnableCustomDialer(server.dialer()).
+		EnableMXResolver(resolver).
+		EnableDisposableCheck(newDisposableRepo())
+
+	emails := make([]string, 50)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("user%d@example.test", i)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			v.FromEmail(fmt.Sprintf("sender%d@example.org", i)).
+				HelloName(fmt.Sprintf("host%d.example.org", i)).
+				AddFreeDomains([]string{fmt.Sprintf("free%d.example", i)}).
+				RoleAccounts([]string{"admin"}).
+				DomainSuggestThreshold(0.9).
+				ScoringWeights(DefaultScoringWeights())
+			if i%2 == 0 {
+				v.DisableCatchAllCheck().EnableMXCache(time.Minute).EnableDomainSuggest()
+				v.FromEmailFunc(func(domain string) string { return "verify@" + domain })
+				_ = v.EnableAPIVerifier(GMAIL, nil)
+			} else {
+				v.EnableCatchAllCheck().DisableMXCache().DisableDomainSuggest()
+				v.FromEmailFunc(nil)
+				v.DisableAPIVerifier(GMAIL)
+			}
+		}
+	}()
+
+	results, err := v.BatchVerify(emails, 8)
+	for _, email := range emails[:10] {
+		_, _ = v.Verify(email)
+		_ = v.IsFreeDomain("example.test")
+		_ = v.IsRoleAccount("admin")
+	}
+	close(done)
+	wg.Wait()
+
+	assert.NoError(t, err)
+	for _, r := range results {
+		assert.Empty(t, r.Error)
+		assert.True(t, r.Syntax.Valid)
+		assert.True(t, r.HasMxRecords)
+	}
+}
+
+func TestVerify_DomainNotFound(t *testing.T) {
+	verifier := NewVerifier().EnableMXResolver(&mockResolver{
+		ns: map[string][]*net.NS{"nomx.test": {{Host: "ns.nomx.test."}}},
+	})
+
+	ret, err := verifier.Verify("someone@nx.test")
+	var mxErr *MXError
+	assert.ErrorAs(t, err, &mxErr)
+	assert.Equal(t, reachableNo, ret.Reachable)
+	assert.Equal(t, ReasonDomainNotFound, ret.Reason)
+
+	ret, err = verifier.Verify("someone@nomx.test")
+	assert.ErrorAs(t, err, &mxErr)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Equal(t, ReasonNoMXRecords, ret.Reason)
+}
+
+func TestVerify_ReachabilityFunc(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	verifier := srv.verifier("example.test", "other.test")
+
+	ret, err := verifier.Verify("john@example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Equal(t, ReasonCatchAll, ret.Reason)
+
+	// The catch-all example.test is trusted, the rest is left to the built-in mapping
+	verifier.SetReachabilityFunc(func(s *SMTP, r *Result) string {
+		if s.CatchAll && r.Syntax.Domain == "example.test" {
+			return reachableYes
+		}
+		return ""
+	})
+	ret, err = verifier.Verify("john@example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableYes, ret.Reachable)
+	assert.Empty(t, ret.Reason)
+
+	ret, err = verifier.Verify("john@other.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+
+	ret, err = verifier.SetReachabilityFunc(nil).Verify("john@example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+}
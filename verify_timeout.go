@@ -0,0 +1,79 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrVerifyTimeout is matched, via errors.Is, by the error of Verify exhausting its budget, see VerifyTimeout
+var ErrVerifyTimeout = errors.New("verification timed out")
+
+// verifyTimeoutError is returned by Verify exhausting its budget, it unwraps to the error
+// of the interrupted stage, e.g. an SMTPError, which is nil when the budget ran out between the stages
+type verifyTimeoutError struct {
+	err error
+}
+
+func (e *verifyTimeoutError) Error() string {
+	if e.err == nil {
+		return ErrVerifyTimeout.Error()
+	}
+	return ErrVerifyTimeout.Error() + ": " + e.err.Error()
+}
+
+func (e *verifyTimeoutError) Unwrap() error        { return e.err }
+func (e *verifyTimeoutError) Is(target error) bool { return target == ErrVerifyTimeout }
+
+// VerifyTimeout sets the budget of the whole Verify, i.e. of the DNS lookups, the SMTP conversation and the other
+// checks together, so its latency is predictable. Verify exhausting the budget returns the partial result with
+// the error matching ErrVerifyTimeout. A non-positive budget disables it (default), leaving the latency
+// bounded by the timeouts of the stages only, e.g. ConnectTimeout.
+func (v *Verifier) VerifyTimeout(budget time.Duration) *Verifier {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if budget < 0 {
+		budget = 0
+	}
+	v.verifyTimeout = budget
+	return v
+}
+
+// context returns the context bounding the network calls by the budget of the running Verify
+func (v *Verifier) context() context.Context {
+	if v.ctx == nil {
+		return context.Background()
+	}
+	return v.ctx
+}
+
+// budgetExceeded reports whether the budget of the running Verify is exhausted. The deadline is checked
+// along with the context, as the connection deadline set to the same instant may expire first.
+func (v *Verifier) budgetExceeded() bool {
+	if v.ctx == nil {
+		return false
+	}
+	if v.ctx.Err() != nil {
+		return true
+	}
+	deadline, ok := v.ctx.Deadline()
+	return ok && !time.Now().Before(deadline)
+}
+
+// timedOut reports whether the stage failed due to the exhausted budget of the running Verify
+func (v *Verifier) timedOut(err error) bool {
+	return v.budgetExceeded() || v.ctx != nil && errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// sleep waits for d, but no longer than the budget of the running Verify lasts
+func (v *Verifier) sleep(d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-v.context().Done():
+	}
+}
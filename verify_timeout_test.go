@@ -0,0 +1,97 @@
+package emailverifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyFailed_TimeoutDialing(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	dialer := slowDialer{mockDialer: mockDialer{addr: srv.ln.Addr().String()}, slow: "mx.", delay: time.Second}
+	verifier := srv.verifier(domain).EnableCustomDialer(dialer).VerifyTimeout(100 * time.Millisecond)
+
+	start := time.Now()
+	ret, err := verifier.Verify("someone@" + domain)
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+	assert.ErrorIs(t, err, ErrVerifyTimeout)
+	var smtpErr *SMTPError
+	assert.True(t, errors.As(err, &smtpErr))
+	// The partial result
+	assert.True(t, ret.Syntax.Valid)
+	assert.True(t, ret.HasMxRecords)
+	assert.Equal(t, reachableUnknown, ret.Reachable)
+	assert.Equal(t, ReasonVerifyTimeout, ret.Reason)
+}
+
+func TestVerifyFailed_TimeoutConversation(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	// The server does not reply to RCPT until the test ends
+	stalled := make(chan struct{})
+	t.Cleanup(func() { close(stalled) })
+	srv.reply = func(cmd, arg string) string {
+		if cmd == "RCPT" {
+			<-stalled
+		}
+		return ""
+	}
+	verifier := srv.verifier(domain).VerifyTimeout(100 * time.Millisecond).EnableResultCache(time.Hour)
+
+	start := time.Now()
+	_, err := verifier.Verify("someone@" + domain)
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+	assert.ErrorIs(t, err, ErrVerifyTimeout)
+
+	// The result of the exhausted budget is not cached
+	_, ok := verifier.resultCache.get(verifier.NormalizeEmail("someone@" + domain))
+	assert.False(t, ok)
+}
+
+// expiredContext has passed its deadline, but is not canceled yet
+type expiredContext struct {
+	context.Context
+}
+
+func (expiredContext) Deadline() (time.Time, bool) { return time.Now().Add(-time.Millisecond), true }
+
+func TestBudgetExceeded_DeadlinePassed(t *testing.T) {
+	v := NewVerifier()
+	assert.False(t, v.budgetExceeded())
+	assert.False(t, v.timedOut(os.ErrDeadlineExceeded))
+
+	// The connection deadline set to the same instant may expire before the context
+	v.ctx = expiredContext{context.Background()}
+	assert.True(t, v.budgetExceeded())
+
+	v.ctx = context.Background()
+	assert.False(t, v.budgetExceeded())
+	assert.True(t, v.timedOut(&net.OpError{Op: "write", Err: os.ErrDeadlineExceeded}))
+	assert.False(t, v.timedOut(errors.New("connection refused")))
+}
+
+func TestVerifyOK_WithinTimeout(t *testing.T) {
+	domain := "example.test"
+	srv := newMockSMTPServer(t)
+	srv.reply = rejectRandomRCPT("someone@" + domain)
+
+	ret, err := srv.verifier(domain).VerifyTimeout(time.Minute).Verify("someone@" + domain)
+	assert.NoError(t, err)
+	assert.Equal(t, reachableYes, ret.Reachable)
+}
+
+func TestVerifyTimeoutError(t *testing.T) {
+	err := error(&verifyTimeoutError{})
+	assert.ErrorIs(t, err, ErrVerifyTimeout)
+	assert.Equal(t, "verification timed out", err.Error())
+
+	err = &verifyTimeoutError{&SMTPError{errors.New("i/o timeout")}}
+	assert.ErrorIs(t, err, ErrVerifyTimeout)
+	assert.Equal(t, "verification timed out: i/o timeout", err.Error())
+}